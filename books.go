@@ -0,0 +1,311 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+type Testament int
+
+const (
+	OldTestament Testament = iota + 1
+	NewTestament
+)
+
+func (t Testament) String() string {
+	switch t {
+	case OldTestament:
+		return "OT"
+	case NewTestament:
+		return "NT"
+	}
+	return ""
+}
+
+type Genre string
+
+const (
+	GenreLaw             Genre = "Law"
+	GenreHistory         Genre = "History"
+	GenreWisdom          Genre = "Wisdom"
+	GenreMajorProphets   Genre = "Major Prophets"
+	GenreMinorProphets   Genre = "Minor Prophets"
+	GenreGospels         Genre = "Gospels"
+	GenrePaulineEpistles Genre = "Pauline Epistles"
+	GenreGeneralEpistles Genre = "General Epistles"
+	GenreApocalyptic     Genre = "Apocalyptic"
+)
+
+// BookInfo describes one book of the canon. Nb is the canonical position
+// (Genesis = 1, Revelation = 66) and Verses holds the verse count of each chapter.
+type BookInfo struct {
+	Nb        int
+	Name      string
+	Testament Testament
+	Genre     Genre
+	SBL       string
+	OSIS      string
+	USFM      string
+	AltNames  []string
+	Verses    []int
+}
+
+func (b *BookInfo) Chapters() int {
+	return len(b.Verses)
+}
+
+func (b *BookInfo) VerseCount(chapter int) int {
+	if chapter < 1 || chapter > len(b.Verses) {
+		return 0
+	}
+	return b.Verses[chapter-1]
+}
+
+func (b *BookInfo) TotalVerses() int {
+	total := 0
+	for _, n := range b.Verses {
+		total += n
+	}
+	return total
+}
+
+// FileName is the name used for the json files, e.g. "1Samuel".
+func (b *BookInfo) FileName() string {
+	return strings.ReplaceAll(b.Name, " ", "")
+}
+
+// Slug is the name used in urls, e.g. "1-samuel".
+func (b *BookInfo) Slug() string {
+	return strings.ReplaceAll(strings.ToLower(b.Name), " ", "-")
+}
+
+var booksFile = initialPath + "/Books.json"
+var bookLookup map[string]*BookInfo
+
+var catalogue = []*BookInfo{
+	{Nb: 1, Name: "Genesis", Testament: OldTestament, Genre: GenreLaw, SBL: "Gen", OSIS: "Gen", USFM: "GEN", AltNames: nil,
+		Verses: []int{31, 25, 24, 26, 32, 22, 24, 22, 29, 32, 32, 20, 18, 24, 21, 16, 27, 33, 38, 18, 34, 24, 20, 67, 34, 35, 46, 22, 35, 43, 55, 32, 20, 31, 29, 43, 36, 30, 23, 23, 57, 38, 34, 34, 28, 34, 31, 22, 33, 26}},
+	{Nb: 2, Name: "Exodus", Testament: OldTestament, Genre: GenreLaw, SBL: "Exod", OSIS: "Exod", USFM: "EXO", AltNames: nil,
+		Verses: []int{22, 25, 22, 31, 23, 30, 25, 32, 35, 29, 10, 51, 22, 31, 27, 36, 16, 27, 25, 26, 36, 31, 33, 18, 40, 37, 21, 43, 46, 38, 18, 35, 23, 35, 35, 38, 29, 31, 43, 38}},
+	{Nb: 3, Name: "Leviticus", Testament: OldTestament, Genre: GenreLaw, SBL: "Lev", OSIS: "Lev", USFM: "LEV", AltNames: nil,
+		Verses: []int{17, 16, 17, 35, 19, 30, 38, 36, 24, 20, 47, 8, 59, 57, 33, 34, 16, 30, 37, 27, 24, 33, 44, 23, 55, 46, 34}},
+	{Nb: 4, Name: "Numbers", Testament: OldTestament, Genre: GenreLaw, SBL: "Num", OSIS: "Num", USFM: "NUM", AltNames: nil,
+		Verses: []int{54, 34, 51, 49, 31, 27, 89, 26, 23, 36, 35, 16, 33, 45, 41, 50, 13, 32, 22, 29, 35, 41, 30, 25, 18, 65, 23, 31, 40, 16, 54, 42, 56, 29, 34, 13}},
+	{Nb: 5, Name: "Deuteronomy", Testament: OldTestament, Genre: GenreLaw, SBL: "Deut", OSIS: "Deut", USFM: "DEU", AltNames: nil,
+		Verses: []int{46, 37, 29, 49, 33, 25, 26, 20, 29, 22, 32, 32, 18, 29, 23, 22, 20, 22, 21, 20, 23, 30, 25, 22, 19, 19, 26, 68, 29, 20, 30, 52, 29, 12}},
+	{Nb: 6, Name: "Joshua", Testament: OldTestament, Genre: GenreHistory, SBL: "Josh", OSIS: "Josh", USFM: "JOS", AltNames: nil,
+		Verses: []int{18, 24, 17, 24, 15, 27, 26, 35, 27, 43, 23, 24, 33, 15, 63, 10, 18, 28, 51, 9, 45, 34, 16, 33}},
+	{Nb: 7, Name: "Judges", Testament: OldTestament, Genre: GenreHistory, SBL: "Judg", OSIS: "Judg", USFM: "JDG", AltNames: nil,
+		Verses: []int{36, 23, 31, 24, 31, 40, 25, 35, 57, 18, 40, 15, 25, 20, 20, 31, 13, 31, 30, 48, 25}},
+	{Nb: 8, Name: "Ruth", Testament: OldTestament, Genre: GenreHistory, SBL: "Ruth", OSIS: "Ruth", USFM: "RUT", AltNames: nil,
+		Verses: []int{22, 23, 18, 22}},
+	{Nb: 9, Name: "1 Samuel", Testament: OldTestament, Genre: GenreHistory, SBL: "1 Sam", OSIS: "1Sam", USFM: "1SA", AltNames: []string{"I Samuel", "First Samuel"},
+		Verses: []int{28, 36, 21, 22, 12, 21, 17, 22, 27, 27, 15, 25, 23, 52, 35, 23, 58, 30, 24, 42, 15, 23, 29, 22, 44, 25, 12, 25, 11, 31, 13}},
+	{Nb: 10, Name: "2 Samuel", Testament: OldTestament, Genre: GenreHistory, SBL: "2 Sam", OSIS: "2Sam", USFM: "2SA", AltNames: []string{"II Samuel", "Second Samuel"},
+		Verses: []int{27, 32, 39, 12, 25, 23, 29, 18, 13, 19, 27, 31, 39, 33, 37, 23, 29, 33, 43, 26, 22, 51, 39, 25}},
+	{Nb: 11, Name: "1 Kings", Testament: OldTestament, Genre: GenreHistory, SBL: "1 Kgs", OSIS: "1Kgs", USFM: "1KI", AltNames: []string{"I Kings", "First Kings"},
+		Verses: []int{53, 46, 28, 34, 18, 38, 51, 66, 28, 29, 43, 33, 34, 31, 34, 34, 24, 46, 21, 43, 29, 53}},
+	{Nb: 12, Name: "2 Kings", Testament: OldTestament, Genre: GenreHistory, SBL: "2 Kgs", OSIS: "2Kgs", USFM: "2KI", AltNames: []string{"II Kings", "Second Kings"},
+		Verses: []int{18, 25, 27, 44, 27, 33, 20, 29, 37, 36, 21, 21, 25, 29, 38, 20, 41, 37, 37, 21, 26, 20, 37, 20, 30}},
+	{Nb: 13, Name: "1 Chronicles", Testament: OldTestament, Genre: GenreHistory, SBL: "1 Chr", OSIS: "1Chr", USFM: "1CH", AltNames: []string{"I Chronicles", "First Chronicles"},
+		Verses: []int{54, 55, 24, 43, 26, 81, 40, 40, 44, 14, 47, 40, 14, 17, 29, 43, 27, 17, 19, 8, 30, 19, 32, 31, 31, 32, 34, 21, 30}},
+	{Nb: 14, Name: "2 Chronicles", Testament: OldTestament, Genre: GenreHistory, SBL: "2 Chr", OSIS: "2Chr", USFM: "2CH", AltNames: []string{"II Chronicles", "Second Chronicles"},
+		Verses: []int{17, 18, 17, 22, 14, 42, 22, 18, 31, 19, 23, 16, 22, 15, 19, 14, 19, 34, 11, 37, 20, 12, 21, 27, 28, 23, 9, 27, 36, 27, 21, 33, 25, 33, 27, 23}},
+	{Nb: 15, Name: "Ezra", Testament: OldTestament, Genre: GenreHistory, SBL: "Ezra", OSIS: "Ezra", USFM: "EZR", AltNames: nil,
+		Verses: []int{11, 70, 13, 24, 17, 22, 28, 36, 15, 44}},
+	{Nb: 16, Name: "Nehemiah", Testament: OldTestament, Genre: GenreHistory, SBL: "Neh", OSIS: "Neh", USFM: "NEH", AltNames: nil,
+		Verses: []int{11, 20, 32, 23, 19, 19, 73, 18, 38, 39, 36, 47, 31}},
+	{Nb: 17, Name: "Esther", Testament: OldTestament, Genre: GenreHistory, SBL: "Esth", OSIS: "Esth", USFM: "EST", AltNames: nil,
+		Verses: []int{22, 23, 15, 17, 14, 14, 10, 17, 32, 3}},
+	{Nb: 18, Name: "Job", Testament: OldTestament, Genre: GenreWisdom, SBL: "Job", OSIS: "Job", USFM: "JOB", AltNames: nil,
+		Verses: []int{22, 13, 26, 21, 27, 30, 21, 22, 35, 22, 20, 25, 28, 22, 35, 22, 16, 21, 29, 29, 34, 30, 17, 25, 6, 14, 23, 28, 25, 31, 40, 22, 33, 37, 16, 33, 24, 41, 30, 24, 34, 17}},
+	{Nb: 19, Name: "Psalms", Testament: OldTestament, Genre: GenreWisdom, SBL: "Ps", OSIS: "Ps", USFM: "PSA", AltNames: []string{"Psalm"},
+		Verses: []int{6, 12, 8, 8, 12, 10, 17, 9, 20, 18, 7, 8, 6, 7, 5, 11, 15, 50, 14, 9, 13, 31, 6, 10, 22, 12, 14, 9, 11, 12, 24, 11, 22, 22, 28, 12, 40, 22, 13, 17, 13, 11, 5, 26, 17, 11, 9, 14, 20, 23, 19, 9, 6, 7, 23, 13, 11, 11, 17, 12, 8, 12, 11, 10, 13, 20, 7, 35, 36, 5, 24, 20, 28, 23, 10, 12, 20, 72, 13, 19, 16, 8, 18, 12, 13, 17, 7, 18, 52, 17, 16, 15, 5, 23, 11, 13, 12, 9, 9, 5, 8, 28, 22, 35, 45, 48, 43, 13, 31, 7, 10, 10, 9, 8, 18, 19, 2, 29, 176, 7, 8, 9, 4, 8, 5, 6, 5, 6, 8, 8, 3, 18, 3, 3, 21, 26, 9, 8, 24, 13, 10, 7, 12, 15, 21, 10, 20, 14, 9, 6}},
+	{Nb: 20, Name: "Proverbs", Testament: OldTestament, Genre: GenreWisdom, SBL: "Prov", OSIS: "Prov", USFM: "PRO", AltNames: nil,
+		Verses: []int{33, 22, 35, 27, 23, 35, 27, 36, 18, 32, 31, 28, 25, 35, 33, 33, 28, 24, 29, 30, 31, 29, 35, 34, 28, 28, 27, 28, 27, 33, 31}},
+	{Nb: 21, Name: "Ecclesiastes", Testament: OldTestament, Genre: GenreWisdom, SBL: "Eccl", OSIS: "Eccl", USFM: "ECC", AltNames: []string{"Qoheleth"},
+		Verses: []int{18, 26, 22, 16, 20, 12, 29, 17, 18, 20, 10, 14}},
+	{Nb: 22, Name: "Song of Solomon", Testament: OldTestament, Genre: GenreWisdom, SBL: "Song", OSIS: "Song", USFM: "SNG", AltNames: []string{"Song of Songs", "Canticles"},
+		Verses: []int{17, 17, 11, 16, 16, 13, 13, 14}},
+	{Nb: 23, Name: "Isaiah", Testament: OldTestament, Genre: GenreMajorProphets, SBL: "Isa", OSIS: "Isa", USFM: "ISA", AltNames: nil,
+		Verses: []int{31, 22, 26, 6, 30, 13, 25, 22, 21, 34, 16, 6, 22, 32, 9, 14, 14, 7, 25, 6, 17, 25, 18, 23, 12, 21, 13, 29, 24, 33, 9, 20, 24, 17, 10, 22, 38, 22, 8, 31, 29, 25, 28, 28, 25, 13, 15, 22, 26, 11, 23, 15, 12, 17, 13, 12, 21, 14, 21, 22, 11, 12, 19, 12, 25, 24}},
+	{Nb: 24, Name: "Jeremiah", Testament: OldTestament, Genre: GenreMajorProphets, SBL: "Jer", OSIS: "Jer", USFM: "JER", AltNames: nil,
+		Verses: []int{19, 37, 25, 31, 31, 30, 34, 22, 26, 25, 23, 17, 27, 22, 21, 21, 27, 23, 15, 18, 14, 30, 40, 10, 38, 24, 22, 17, 32, 24, 40, 44, 26, 22, 19, 32, 21, 28, 18, 16, 18, 22, 13, 30, 5, 28, 7, 47, 39, 46, 64, 34}},
+	{Nb: 25, Name: "Lamentations", Testament: OldTestament, Genre: GenreMajorProphets, SBL: "Lam", OSIS: "Lam", USFM: "LAM", AltNames: nil,
+		Verses: []int{22, 22, 66, 22, 22}},
+	{Nb: 26, Name: "Ezekiel", Testament: OldTestament, Genre: GenreMajorProphets, SBL: "Ezek", OSIS: "Ezek", USFM: "EZK", AltNames: nil,
+		Verses: []int{28, 10, 27, 17, 17, 14, 27, 18, 11, 22, 25, 28, 23, 23, 8, 63, 24, 32, 14, 49, 32, 31, 49, 27, 17, 21, 36, 26, 21, 26, 18, 32, 33, 31, 15, 38, 28, 23, 29, 49, 26, 20, 27, 31, 25, 24, 23, 35}},
+	{Nb: 27, Name: "Daniel", Testament: OldTestament, Genre: GenreMajorProphets, SBL: "Dan", OSIS: "Dan", USFM: "DAN", AltNames: nil,
+		Verses: []int{21, 49, 30, 37, 31, 28, 28, 27, 27, 21, 45, 13}},
+	{Nb: 28, Name: "Hosea", Testament: OldTestament, Genre: GenreMinorProphets, SBL: "Hos", OSIS: "Hos", USFM: "HOS", AltNames: nil,
+		Verses: []int{11, 23, 5, 19, 15, 11, 16, 14, 17, 15, 12, 14, 16, 9}},
+	{Nb: 29, Name: "Joel", Testament: OldTestament, Genre: GenreMinorProphets, SBL: "Joel", OSIS: "Joel", USFM: "JOL", AltNames: nil,
+		Verses: []int{20, 32, 21}},
+	{Nb: 30, Name: "Amos", Testament: OldTestament, Genre: GenreMinorProphets, SBL: "Amos", OSIS: "Amos", USFM: "AMO", AltNames: nil,
+		Verses: []int{15, 16, 15, 13, 27, 14, 17, 14, 15}},
+	{Nb: 31, Name: "Obadiah", Testament: OldTestament, Genre: GenreMinorProphets, SBL: "Obad", OSIS: "Obad", USFM: "OBA", AltNames: nil,
+		Verses: []int{21}},
+	{Nb: 32, Name: "Jonah", Testament: OldTestament, Genre: GenreMinorProphets, SBL: "Jonah", OSIS: "Jonah", USFM: "JON", AltNames: nil,
+		Verses: []int{17, 10, 10, 11}},
+	{Nb: 33, Name: "Micah", Testament: OldTestament, Genre: GenreMinorProphets, SBL: "Mic", OSIS: "Mic", USFM: "MIC", AltNames: nil,
+		Verses: []int{16, 13, 12, 13, 15, 16, 20}},
+	{Nb: 34, Name: "Nahum", Testament: OldTestament, Genre: GenreMinorProphets, SBL: "Nah", OSIS: "Nah", USFM: "NAM", AltNames: nil,
+		Verses: []int{15, 13, 19}},
+	{Nb: 35, Name: "Habakkuk", Testament: OldTestament, Genre: GenreMinorProphets, SBL: "Hab", OSIS: "Hab", USFM: "HAB", AltNames: nil,
+		Verses: []int{17, 20, 19}},
+	{Nb: 36, Name: "Zephaniah", Testament: OldTestament, Genre: GenreMinorProphets, SBL: "Zeph", OSIS: "Zeph", USFM: "ZEP", AltNames: nil,
+		Verses: []int{18, 15, 20}},
+	{Nb: 37, Name: "Haggai", Testament: OldTestament, Genre: GenreMinorProphets, SBL: "Hag", OSIS: "Hag", USFM: "HAG", AltNames: nil,
+		Verses: []int{15, 23}},
+	{Nb: 38, Name: "Zechariah", Testament: OldTestament, Genre: GenreMinorProphets, SBL: "Zech", OSIS: "Zech", USFM: "ZEC", AltNames: nil,
+		Verses: []int{21, 13, 10, 14, 11, 15, 14, 23, 17, 12, 17, 14, 9, 21}},
+	{Nb: 39, Name: "Malachi", Testament: OldTestament, Genre: GenreMinorProphets, SBL: "Mal", OSIS: "Mal", USFM: "MAL", AltNames: nil,
+		Verses: []int{14, 17, 18, 6}},
+	{Nb: 40, Name: "Matthew", Testament: NewTestament, Genre: GenreGospels, SBL: "Matt", OSIS: "Matt", USFM: "MAT", AltNames: nil,
+		Verses: []int{25, 23, 17, 25, 48, 34, 29, 34, 38, 42, 30, 50, 58, 36, 39, 28, 27, 35, 30, 34, 46, 46, 39, 51, 46, 75, 66, 20}},
+	{Nb: 41, Name: "Mark", Testament: NewTestament, Genre: GenreGospels, SBL: "Mark", OSIS: "Mark", USFM: "MRK", AltNames: nil,
+		Verses: []int{45, 28, 35, 41, 43, 56, 37, 38, 50, 52, 33, 44, 37, 72, 47, 20}},
+	{Nb: 42, Name: "Luke", Testament: NewTestament, Genre: GenreGospels, SBL: "Luke", OSIS: "Luke", USFM: "LUK", AltNames: nil,
+		Verses: []int{80, 52, 38, 44, 39, 49, 50, 56, 62, 42, 54, 59, 35, 35, 32, 31, 37, 43, 48, 47, 38, 71, 56, 53}},
+	{Nb: 43, Name: "John", Testament: NewTestament, Genre: GenreGospels, SBL: "John", OSIS: "John", USFM: "JHN", AltNames: nil,
+		Verses: []int{51, 25, 36, 54, 47, 71, 53, 59, 41, 42, 57, 50, 38, 31, 27, 33, 26, 40, 42, 31, 25}},
+	{Nb: 44, Name: "Acts", Testament: NewTestament, Genre: GenreHistory, SBL: "Acts", OSIS: "Acts", USFM: "ACT", AltNames: []string{"Acts of the Apostles"},
+		Verses: []int{26, 47, 26, 37, 42, 15, 60, 40, 43, 48, 30, 25, 52, 28, 41, 40, 34, 28, 41, 38, 40, 30, 35, 27, 27, 32, 44, 31}},
+	{Nb: 45, Name: "Romans", Testament: NewTestament, Genre: GenrePaulineEpistles, SBL: "Rom", OSIS: "Rom", USFM: "ROM", AltNames: nil,
+		Verses: []int{32, 29, 31, 25, 21, 23, 25, 39, 33, 21, 36, 21, 14, 23, 33, 27}},
+	{Nb: 46, Name: "1 Corinthians", Testament: NewTestament, Genre: GenrePaulineEpistles, SBL: "1 Cor", OSIS: "1Cor", USFM: "1CO", AltNames: []string{"I Corinthians", "First Corinthians"},
+		Verses: []int{31, 16, 23, 21, 13, 20, 40, 13, 27, 33, 34, 31, 13, 40, 58, 24}},
+	{Nb: 47, Name: "2 Corinthians", Testament: NewTestament, Genre: GenrePaulineEpistles, SBL: "2 Cor", OSIS: "2Cor", USFM: "2CO", AltNames: []string{"II Corinthians", "Second Corinthians"},
+		Verses: []int{24, 17, 18, 18, 21, 18, 16, 24, 15, 18, 33, 21, 14}},
+	{Nb: 48, Name: "Galatians", Testament: NewTestament, Genre: GenrePaulineEpistles, SBL: "Gal", OSIS: "Gal", USFM: "GAL", AltNames: nil,
+		Verses: []int{24, 21, 29, 31, 26, 18}},
+	{Nb: 49, Name: "Ephesians", Testament: NewTestament, Genre: GenrePaulineEpistles, SBL: "Eph", OSIS: "Eph", USFM: "EPH", AltNames: nil,
+		Verses: []int{23, 22, 21, 32, 33, 24}},
+	{Nb: 50, Name: "Philippians", Testament: NewTestament, Genre: GenrePaulineEpistles, SBL: "Phil", OSIS: "Phil", USFM: "PHP", AltNames: nil,
+		Verses: []int{30, 30, 21, 23}},
+	{Nb: 51, Name: "Colossians", Testament: NewTestament, Genre: GenrePaulineEpistles, SBL: "Col", OSIS: "Col", USFM: "COL", AltNames: nil,
+		Verses: []int{29, 23, 25, 18}},
+	{Nb: 52, Name: "1 Thessalonians", Testament: NewTestament, Genre: GenrePaulineEpistles, SBL: "1 Thess", OSIS: "1Thess", USFM: "1TH", AltNames: []string{"I Thessalonians", "First Thessalonians"},
+		Verses: []int{10, 20, 13, 18, 28}},
+	{Nb: 53, Name: "2 Thessalonians", Testament: NewTestament, Genre: GenrePaulineEpistles, SBL: "2 Thess", OSIS: "2Thess", USFM: "2TH", AltNames: []string{"II Thessalonians", "Second Thessalonians"},
+		Verses: []int{12, 17, 18}},
+	{Nb: 54, Name: "1 Timothy", Testament: NewTestament, Genre: GenrePaulineEpistles, SBL: "1 Tim", OSIS: "1Tim", USFM: "1TI", AltNames: []string{"I Timothy", "First Timothy"},
+		Verses: []int{20, 15, 16, 16, 25, 21}},
+	{Nb: 55, Name: "2 Timothy", Testament: NewTestament, Genre: GenrePaulineEpistles, SBL: "2 Tim", OSIS: "2Tim", USFM: "2TI", AltNames: []string{"II Timothy", "Second Timothy"},
+		Verses: []int{18, 26, 17, 22}},
+	{Nb: 56, Name: "Titus", Testament: NewTestament, Genre: GenrePaulineEpistles, SBL: "Titus", OSIS: "Titus", USFM: "TIT", AltNames: nil,
+		Verses: []int{16, 15, 15}},
+	{Nb: 57, Name: "Philemon", Testament: NewTestament, Genre: GenrePaulineEpistles, SBL: "Phlm", OSIS: "Phlm", USFM: "PHM", AltNames: nil,
+		Verses: []int{25}},
+	{Nb: 58, Name: "Hebrews", Testament: NewTestament, Genre: GenreGeneralEpistles, SBL: "Heb", OSIS: "Heb", USFM: "HEB", AltNames: nil,
+		Verses: []int{14, 18, 19, 16, 14, 20, 28, 13, 28, 39, 40, 29, 25}},
+	{Nb: 59, Name: "James", Testament: NewTestament, Genre: GenreGeneralEpistles, SBL: "Jas", OSIS: "Jas", USFM: "JAS", AltNames: nil,
+		Verses: []int{27, 26, 18, 17, 20}},
+	{Nb: 60, Name: "1 Peter", Testament: NewTestament, Genre: GenreGeneralEpistles, SBL: "1 Pet", OSIS: "1Pet", USFM: "1PE", AltNames: []string{"I Peter", "First Peter"},
+		Verses: []int{25, 25, 22, 19, 14}},
+	{Nb: 61, Name: "2 Peter", Testament: NewTestament, Genre: GenreGeneralEpistles, SBL: "2 Pet", OSIS: "2Pet", USFM: "2PE", AltNames: []string{"II Peter", "Second Peter"},
+		Verses: []int{21, 22, 18}},
+	{Nb: 62, Name: "1 John", Testament: NewTestament, Genre: GenreGeneralEpistles, SBL: "1 John", OSIS: "1John", USFM: "1JN", AltNames: []string{"I John", "First John"},
+		Verses: []int{10, 29, 24, 21, 21}},
+	{Nb: 63, Name: "2 John", Testament: NewTestament, Genre: GenreGeneralEpistles, SBL: "2 John", OSIS: "2John", USFM: "2JN", AltNames: []string{"II John", "Second John"},
+		Verses: []int{13}},
+	{Nb: 64, Name: "3 John", Testament: NewTestament, Genre: GenreGeneralEpistles, SBL: "3 John", OSIS: "3John", USFM: "3JN", AltNames: []string{"III John", "Third John"},
+		Verses: []int{14}},
+	{Nb: 65, Name: "Jude", Testament: NewTestament, Genre: GenreGeneralEpistles, SBL: "Jude", OSIS: "Jude", USFM: "JUD", AltNames: nil,
+		Verses: []int{25}},
+	{Nb: 66, Name: "Revelation", Testament: NewTestament, Genre: GenreApocalyptic, SBL: "Rev", OSIS: "Rev", USFM: "REV", AltNames: []string{"Revelations", "Apocalypse"},
+		Verses: []int{20, 29, 22, 11, 14, 17, 17, 13, 21, 11, 19, 17, 18, 20, 8, 21, 18, 24, 21, 15, 27, 21}},
+}
+
+func init() {
+	bookLookup = make(map[string]*BookInfo)
+	for _, b := range catalogue {
+		keys := []string{b.Name, b.SBL, b.OSIS, b.USFM}
+		keys = append(keys, b.AltNames...)
+		for _, k := range keys {
+			bookLookup[bookKey(k)] = b
+		}
+	}
+}
+
+func bookKey(name string) string {
+	name = accents.Replace(strings.ToLower(strings.TrimSpace(name)))
+	name = strings.ReplaceAll(name, ".", "")
+	return strings.ReplaceAll(name, " ", "")
+}
+
+// bookInfo finds a book by its name, any of its abbreviations or an alternate
+// name, in English or in one of the locales.
+func bookInfo(name string) *BookInfo {
+	key := bookKey(name)
+	if info, ok := bookLookup[key]; ok {
+		return info
+	}
+	return localeBookInfo(key)
+}
+
+func mustBookInfo(name string) *BookInfo {
+	info := bookInfo(name)
+	if info == nil {
+		panic(fmt.Errorf("unknown book: %s", name))
+	}
+	return info
+}
+
+func bookByNb(nb int) *BookInfo {
+	if nb < 1 || nb > len(catalogue) {
+		return nil
+	}
+	return catalogue[nb-1]
+}
+
+func booksByTestament(t Testament) []*BookInfo {
+	var books []*BookInfo
+	for _, b := range catalogue {
+		if b.Testament == t {
+			books = append(books, b)
+		}
+	}
+	return books
+}
+
+func booksByGenre(g Genre) []*BookInfo {
+	var books []*BookInfo
+	for _, b := range catalogue {
+		if b.Genre == g {
+			books = append(books, b)
+		}
+	}
+	return books
+}
+
+// readBookNames reads the book names of Books.json in their order.
+func readBookNames() ([]string, error) {
+	bytes, err := ioutil.ReadFile(booksFile)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	if err := json.Unmarshal(bytes, &names); err != nil {
+		return nil, fmt.Errorf("failed to read book names: %w", err)
+	}
+	return names, nil
+}
+
+// verifyBookNames makes sure Books.json still lists the books in catalogue order.
+func verifyBookNames() {
+	names, err := readBookNames()
+	if err != nil {
+		panic(err)
+	}
+	if len(names) != len(catalogue) {
+		panic(fmt.Errorf("expected %d books in %s, got %d", len(catalogue), booksFile, len(names)))
+	}
+	for i, name := range names {
+		if catalogue[i].Name != name {
+			panic(fmt.Errorf("book %d in %s is %s, expecting %s", i+1, booksFile, name, catalogue[i].Name))
+		}
+	}
+}
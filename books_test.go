@@ -0,0 +1,105 @@
+package main
+
+import "testing"
+
+func TestBookInfo(t *testing.T) {
+	tests := []struct {
+		name string
+		nb   int
+	}{
+		{"Genesis", 1},
+		{"gen", 1},
+		{"GEN", 1},
+		{"1 Sam", 9},
+		{"1Sam", 9},
+		{"I Samuel", 9},
+		{"First Samuel", 9},
+		{"1 Sam.", 9},
+		{"Song of Songs", 22},
+		{"PSA", 19},
+		{"  Psalms ", 19},
+		{"1John", 62},
+		{"John", 43},
+		{"Revelations", 66},
+		{"Hezekiah", 0},
+		{"", 0},
+	}
+	for _, test := range tests {
+		info := bookInfo(test.name)
+		nb := 0
+		if info != nil {
+			nb = info.Nb
+		}
+		if nb != test.nb {
+			t.Errorf("bookInfo(%q) = %d, expecting %d", test.name, nb, test.nb)
+		}
+	}
+}
+
+func TestBookCatalogue(t *testing.T) {
+	tests := []struct {
+		nb              int
+		fileName, slug  string
+		chapters, total int
+		testament       Testament
+	}{
+		{1, "Genesis", "genesis", 50, 1533, OldTestament},
+		{9, "1Samuel", "1-samuel", 31, 810, OldTestament},
+		{19, "Psalms", "psalms", 150, 2461, OldTestament},
+		{31, "Obadiah", "obadiah", 1, 21, OldTestament},
+		{40, "Matthew", "matthew", 28, 1071, NewTestament},
+		{66, "Revelation", "revelation", 22, 404, NewTestament},
+	}
+	for _, test := range tests {
+		b := bookByNb(test.nb)
+		if b.FileName() != test.fileName || b.Slug() != test.slug {
+			t.Errorf("book %d is named %s and %s, expecting %s and %s", test.nb, b.FileName(), b.Slug(), test.fileName, test.slug)
+		}
+		if b.Chapters() != test.chapters || b.TotalVerses() != test.total {
+			t.Errorf("%s has %d chapters and %d verses, expecting %d and %d", b.Name, b.Chapters(), b.TotalVerses(), test.chapters, test.total)
+		}
+		if b.Testament != test.testament {
+			t.Errorf("%s is in the %s, expecting %s", b.Name, b.Testament, test.testament)
+		}
+	}
+	if n := len(booksByTestament(OldTestament)); n != 39 {
+		t.Errorf("%d books in the OT, expecting 39", n)
+	}
+	if n := len(booksByTestament(NewTestament)); n != 27 {
+		t.Errorf("%d books in the NT, expecting 27", n)
+	}
+	if n := len(booksByGenre(GenreGospels)); n != 4 {
+		t.Errorf("%d gospels, expecting 4", n)
+	}
+	total := 0
+	for _, b := range catalogue {
+		total += b.TotalVerses()
+	}
+	if total != 31102 {
+		t.Errorf("%d verses in the catalogue, expecting 31102", total)
+	}
+	for _, nb := range []int{0, 67} {
+		if b := bookByNb(nb); b != nil {
+			t.Errorf("bookByNb(%d) = %s, expecting nil", nb, b.Name)
+		}
+	}
+	if n := bookByNb(19).VerseCount(119); n != 176 {
+		t.Errorf("Psalm 119 has %d verses, expecting 176", n)
+	}
+	if n := bookByNb(19).VerseCount(151); n != 0 {
+		t.Errorf("Psalm 151 has %d verses, expecting 0", n)
+	}
+}
+
+func TestVerifyBookNames(t *testing.T) {
+	names, err := readBookNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range names {
+		if bookInfo(name) != catalogue[i] {
+			t.Errorf("%s in %s doesn't find book %d", name, booksFile, i+1)
+		}
+	}
+	verifyBookNames()
+}
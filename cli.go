@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+var commands = map[string]func(args []string){
+	"export":      exportCommand,
+	"crossrefs":   crossRefsCommand,
+	"strongs":     strongsCommand,
+	"usfm":        usfmCommand,
+	"read":        readCommand,
+	"site":        siteCommand,
+	"epub":        epubCommand,
+	"vault":       vaultCommand,
+	"flat":        flatCommand,
+	"zefania":     zefaniaCommand,
+	"opensong":    openSongCommand,
+	"import":      importCommand,
+	"merge":       mergeCommand,
+	"parallel":    parallelCommand,
+	"versify":     versifyCommand,
+	"stats":       statsCommand,
+	"related":     relatedCommand,
+	"search":      searchCommand,
+	"typography":  typographyCommand,
+	"lint-titles": lintTitlesCommand,
+}
+
+func runCommand(name string, args []string) {
+	cmd, ok := commands[name]
+	if !ok {
+		var names []string
+		for n := range commands {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(os.Stderr, "unknown command: %s, expecting one of %v\n", name, names)
+		os.Exit(2)
+	}
+	cmd(args)
+}
+
+// loadEnhancedData loads the enhanced books with every optional dataset found on disk applied.
+func loadEnhancedData() {
+	loadEnhancedBooks()
+	applyPsalmHeadings()
+	if _, err := os.Stat(superscriptionsPath); err == nil {
+		if err := loadSuperscriptions(superscriptionsPath); err != nil {
+			logError(err)
+		}
+		applySuperscriptions()
+	}
+	if _, err := os.Stat(crossRefsPath); err == nil {
+		if err := loadCrossRefs(crossRefsPath); err != nil {
+			logError(err)
+		}
+		applyCrossRefs()
+	}
+	if _, err := os.Stat(strongsPath); err == nil {
+		if err := loadStrongs(strongsPath); err != nil {
+			logError(err)
+		}
+		applyStrongs()
+	}
+	if _, err := os.Stat(redLetterPath); err == nil {
+		if err := loadRedLetters(redLetterPath); err != nil {
+			logError(err)
+		}
+		applyRedLetters()
+	}
+	for _, file := range summariesPaths {
+		if _, err := os.Stat(file); err != nil {
+			continue
+		}
+		entries, err := loadSummaries(file)
+		if err != nil {
+			logError(err)
+			continue
+		}
+		applySummaries(entries)
+	}
+}
+
+// loadEnhancedDataQuiet loads the data with the progress messages sent to
+// stderr, for the commands whose output is meant to be piped.
+func loadEnhancedDataQuiet() {
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = stdout }()
+	loadEnhancedData()
+}
+
+// exportCommand rewrites the enhanced books with every optional dataset applied.
+func exportCommand(args []string) {
+	loadEnhancedData()
+	writeEnhancedBooks(os.Stdout)
+}
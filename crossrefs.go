@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CrossRef points from a verse or passage to a related verse or passage. Votes
+// comes from OpenBible style lists and is 0 for sources without a ranking, like the TSK.
+type CrossRef struct {
+	From  VerseRange `json:"from"`
+	To    VerseRange `json:"to"`
+	Votes int        `json:"votes,omitempty"`
+}
+
+var crossRefsPath = "./json/crossrefs.tsv"
+var crossRefs = make(map[VerseID][]CrossRef)
+
+// loadCrossRefs reads a tab separated cross reference list. Each line holds the
+// source verse, the target verse or range and optionally a vote count, e.g.
+// "Gen.1.1	Prov.8.22-Prov.8.30	59" or "Genesis 1:1	John 1:1-3".
+// Lines starting with # and a header line are skipped. A source range gives its
+// references to each of its verses. The references loaded before are replaced.
+func loadCrossRefs(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("failed to open cross references %s: %w", file, err)
+	}
+	defer f.Close()
+
+	fmt.Println("reading cross references...")
+	crossRefs = make(map[VerseID][]CrossRef)
+	count, skipped := 0, 0
+	scanner := bufio.NewScanner(f)
+	lineNb := 0
+	for scanner.Scan() {
+		lineNb++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		cols := strings.Split(line, "\t")
+		if len(cols) < 2 {
+			logError(fmt.Errorf("%s:%d: expecting at least 2 columns", file, lineNb))
+			skipped++
+			continue
+		}
+		from, err := parseReference(cols[0])
+		if err != nil {
+			if lineNb == 1 {
+				// header
+				continue
+			}
+			logError(fmt.Errorf("%s:%d: %w", file, lineNb, err))
+			skipped++
+			continue
+		}
+		for _, target := range strings.Split(cols[1], ";") {
+			to, err := parseCrossRefTarget(target)
+			if err == nil {
+				err = verifyCrossRefTarget(to)
+			}
+			if err != nil {
+				logError(fmt.Errorf("%s:%d: %w", file, lineNb, err))
+				skipped++
+				continue
+			}
+			ref := CrossRef{From: from, To: to}
+			if len(cols) > 2 {
+				ref.Votes, _ = strconv.Atoi(strings.TrimSpace(cols[2]))
+			}
+			for _, id := range from.IDs() {
+				crossRefs[id] = append(crossRefs[id], ref)
+			}
+			count++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read cross references %s: %w", file, err)
+	}
+
+	for id := range crossRefs {
+		refs := crossRefs[id]
+		sort.SliceStable(refs, func(i, j int) bool {
+			if refs[i].Votes != refs[j].Votes {
+				return refs[i].Votes > refs[j].Votes
+			}
+			return refs[i].To.Start < refs[j].To.Start
+		})
+	}
+	fmt.Printf("read %v cross references, skipped %v\n", count, skipped)
+	return nil
+}
+
+// parseCrossRefTarget handles the OSIS ranges used by OpenBible ("Prov.8.22-Prov.8.30")
+// on top of the references understood by parseReference.
+func parseCrossRefTarget(target string) (VerseRange, error) {
+	target = strings.TrimSpace(target)
+	if parts := strings.Split(target, "-"); len(parts) == 2 {
+		start, err := parseOSISID(parts[0])
+		if err == nil {
+			end, err := parseOSISID(parts[1])
+			if err != nil {
+				return VerseRange{}, err
+			}
+			if end < start {
+				return VerseRange{}, fmt.Errorf("reference ends before it starts: %s", target)
+			}
+			return VerseRange{Start: start, End: end}, nil
+		}
+	}
+	return parseReference(target)
+}
+
+// verifyCrossRefTarget makes sure the target exists in the loaded books, or in
+// the catalogue when none are loaded.
+func verifyCrossRefTarget(r VerseRange) error {
+	if len(enhancedVerses) == 0 {
+		if !r.Start.Valid() || !r.End.Valid() {
+			return fmt.Errorf("cross reference target does not exist: %s", r)
+		}
+		return nil
+	}
+	for _, id := range []VerseID{r.Start, r.End} {
+		if _, ok := enhancedVerses[id]; !ok {
+			return fmt.Errorf("cross reference target not found: %s", id)
+		}
+	}
+	return nil
+}
+
+// CrossRefs returns the cross references of every verse in ref, e.g. "Gen 1:1"
+// or "John 3", each with the verse or passage it comes from. A reference from a
+// passage is returned once.
+func CrossRefs(ref string) ([]CrossRef, error) {
+	r, err := parseReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	var refs []CrossRef
+	seen := make(map[CrossRef]bool)
+	for _, id := range r.IDs() {
+		for _, cr := range crossRefs[id] {
+			if !seen[cr] {
+				seen[cr] = true
+				refs = append(refs, cr)
+			}
+		}
+	}
+	return refs, nil
+}
+
+func applyCrossRefs() {
+	count := 0
+	for id, refs := range crossRefs {
+		if v, ok := enhancedVerses[id]; ok {
+			v.CrossRefs = refs
+			count++
+		}
+	}
+	fmt.Printf("applied cross references to %v verses!\n", count)
+}
+
+func crossRefsCommand(args []string) {
+	fs := flag.NewFlagSet("crossrefs", flag.ExitOnError)
+	file := fs.String("file", crossRefsPath, "tab separated cross reference list")
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: crossrefs [-file path] <reference>")
+		os.Exit(2)
+	}
+
+	if err := loadCrossRefs(*file); err != nil {
+		log.Fatal(err)
+	}
+	refs, err := CrossRefs(strings.Join(fs.Args(), " "))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, ref := range refs {
+		if ref.Votes != 0 {
+			fmt.Printf("%s\t%s (%d)\n", ref.From, ref.To, ref.Votes)
+		} else {
+			fmt.Printf("%s\t%s\n", ref.From, ref.To)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func loadTestCrossRefs(t *testing.T, data string) {
+	saved := crossRefs
+	t.Cleanup(func() { crossRefs = saved })
+	useBooks(t, nil)
+	if err := loadCrossRefs(writeTestFile(t, "crossrefs.tsv", data)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadCrossRefs(t *testing.T) {
+	loadTestCrossRefs(t, "From Verse\tTo Verse\tVotes\n"+
+		"Gen.1.1\tJohn.1.1-John.1.3\t120\n"+
+		"Gen.1.1\tProv.8.22-Prov.8.30\t59\n"+
+		"Gen 1:1-2\tHeb 11:3; Ps 33:6\n"+
+		"# a comment\n")
+	r := func(ref string) VerseRange {
+		vr, err := parseReference(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return vr
+	}
+	tests := []struct {
+		id   VerseID
+		want []CrossRef
+	}{
+		{newVerseID(1, 1, 1), []CrossRef{
+			{From: r("Gen 1:1"), To: r("John 1:1-3"), Votes: 120},
+			{From: r("Gen 1:1"), To: r("Prov 8:22-30"), Votes: 59},
+			{From: r("Gen 1:1-2"), To: r("Ps 33:6")},
+			{From: r("Gen 1:1-2"), To: r("Heb 11:3")},
+		}},
+		{newVerseID(1, 1, 2), []CrossRef{
+			{From: r("Gen 1:1-2"), To: r("Ps 33:6")},
+			{From: r("Gen 1:1-2"), To: r("Heb 11:3")},
+		}},
+	}
+	for _, test := range tests {
+		if got := crossRefs[test.id]; !reflect.DeepEqual(got, test.want) {
+			t.Errorf("cross references of %s are %v, expecting %v", test.id, got, test.want)
+		}
+	}
+}
+
+func TestCrossRefs(t *testing.T) {
+	loadTestCrossRefs(t, "Gen 1:1-2\tHeb 11:3\nGen 1:3\tJohn 1:5\t4\nGen 2:1\tEx 20:11\n")
+	refs, err := CrossRefs("Gen 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Genesis 1:1-2 Hebrews 11:3", "Genesis 1:3 John 1:5"}
+	var got []string
+	for _, ref := range refs {
+		got = append(got, ref.From.String()+" "+ref.To.String())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CrossRefs(Gen 1) = %v, expecting %v", got, want)
+	}
+	if _, err := CrossRefs("Gen 51"); err == nil {
+		t.Error("CrossRefs(Gen 51) gave no error")
+	}
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var epubPath = "./kjv.epub"
+
+// epubModified is the default modification date of the book, fixed so the same
+// data always gives the same file.
+var epubModified = "2024-01-01T00:00:00Z"
+
+var epubFuncs = template.FuncMap{
+	"esc": html.EscapeString,
+}
+
+var epubContainer = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+var epubStyle = `body { font-family: serif; line-height: 1.5; }
+h1 { text-align: center; page-break-before: always; }
+h2.chapter { text-align: center; margin-top: 2em; }
+h3.section { font-size: 1em; margin-top: 1.5em; }
+.superscription, .summary { font-style: italic; }
+.nb { font-size: .7em; vertical-align: super; }
+.poetry .line { display: block; margin-left: 1.5em; text-indent: -1em; }
+.wj { color: #a00; }
+em.add { font-style: italic; }
+`
+
+var epubPackage = template.Must(template.New("content.opf").Funcs(epubFuncs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="bookid" xml:lang="{{.Lang}}">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="bookid">{{.ID}}</dc:identifier>
+    <dc:title>{{esc .Title}}</dc:title>
+    <dc:language>{{.Lang}}</dc:language>
+    <dc:rights>Public domain</dc:rights>
+    <meta property="dcterms:modified">{{.Modified}}</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="style" href="style.css" media-type="text/css"/>
+{{- range .Books}}
+    <item id="{{.ID}}" href="{{.File}}" media-type="application/xhtml+xml"/>
+{{- end}}
+  </manifest>
+  <spine>
+{{- range .Books}}
+    <itemref idref="{{.ID}}"/>
+{{- end}}
+  </spine>
+</package>
+`))
+
+var epubNav = template.Must(template.New("nav.xhtml").Funcs(epubFuncs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="{{.Lang}}" lang="{{.Lang}}">
+<head>
+<title>{{esc .Title}}</title>
+</head>
+<body>
+<nav epub:type="toc" id="toc">
+<h1>{{esc .Title}}</h1>
+<ol>
+{{- range .Books}}
+<li><a href="{{.File}}">{{esc .Name}}</a>
+<ol>
+{{- $file := .File}}
+{{- range .Chapters}}
+<li><a href="{{$file}}#{{.Anchor}}">{{.Nb}}</a>
+{{- if .Sections}}
+<ol>
+{{- range .Sections}}
+<li><a href="{{$file}}#{{.Anchor}}">{{esc .Title}}</a></li>
+{{- end}}
+</ol>
+{{- end}}
+</li>
+{{- end}}
+</ol>
+</li>
+{{- end}}
+</ol>
+</nav>
+</body>
+</html>
+`))
+
+var epubBook = template.Must(template.New("book.xhtml").Funcs(epubFuncs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="{{.Lang}}" lang="{{.Lang}}">
+<head>
+<title>{{esc .Name}}</title>
+<link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+<h1>{{esc .Name}}</h1>
+{{- range .Introduction}}
+<p class="introduction">{{esc .}}</p>
+{{- end}}
+{{- range .Chapters}}
+<section id="{{.Anchor}}" epub:type="chapter">
+<h2 class="chapter">{{if $.Psalms}}Psalm{{else}}Chapter{{end}} {{.Nb}}</h2>
+{{- if .Summary}}
+<p class="summary">{{esc .Summary}}</p>
+{{- end}}
+{{- if .Superscription}}
+<p class="superscription">{{esc .Superscription}}</p>
+{{- end}}
+{{- range .Paragraphs}}
+{{- if .Title}}
+<h3 class="section" id="{{.Anchor}}">{{esc .Title}}</h3>
+{{- end}}
+{{- if .StanzaHeading}}
+<h4 class="stanza">{{esc .StanzaHeading}}</h4>
+{{- end}}
+<p{{if .Poetry}} class="poetry"{{end}}>
+{{- range .Verses}}
+<span id="{{.Anchor}}"><span class="nb">{{.Nb}}</span> {{.HTML}}</span>
+{{- end}}
+</p>
+{{- end}}
+</section>
+{{- end}}
+</body>
+</html>
+`))
+
+type epubSection struct {
+	Anchor string
+	Title  string
+}
+
+type epubVerse struct {
+	Anchor string
+	Nb     int
+	HTML   string
+}
+
+type epubParagraph struct {
+	Title         string
+	Anchor        string
+	StanzaHeading string
+	Poetry        bool
+	Verses        []epubVerse
+}
+
+type epubChapter struct {
+	Anchor         string
+	Nb             int
+	Summary        string
+	Superscription string
+	Sections       []epubSection
+	Paragraphs     []epubParagraph
+}
+
+type epubBookData struct {
+	Lang         string
+	ID           string
+	File         string
+	Name         string
+	Psalms       bool
+	Introduction []string
+	Chapters     []epubChapter
+}
+
+type epubData struct {
+	Lang     string
+	ID       string
+	Title    string
+	Modified string
+	Books    []epubBookData
+}
+
+func epubBookContent(book *BookEnhanced) epubBookData {
+	info := mustBookInfo(book.Title)
+	data := epubBookData{
+		Lang:   displayLanguage(),
+		ID:     "b" + info.OSIS,
+		File:   fmt.Sprintf("%02d-%s.xhtml", info.Nb, info.FileName()),
+		Name:   info.DisplayName(),
+		Psalms: info.OSIS == "Ps",
+	}
+	for _, par := range strings.Split(book.Introduction, "\n\n") {
+		if par = strings.TrimSpace(par); par != "" {
+			data.Introduction = append(data.Introduction, par)
+		}
+	}
+	for _, c := range book.Chapters {
+		chapter := epubChapter{
+			Anchor:         fmt.Sprintf("c%d", c.Nb),
+			Nb:             c.Nb,
+			Summary:        c.Summary,
+			Superscription: c.Superscription,
+		}
+		for _, p := range siteParagraphs(c, info.Poetic()) {
+			ep := epubParagraph{Title: p.Title, StanzaHeading: p.StanzaHeading, Poetry: p.Poetry}
+			if p.Title != "" {
+				ep.Anchor = fmt.Sprintf("c%ds%d", c.Nb, p.Verses[0].Nb)
+				chapter.Sections = append(chapter.Sections, epubSection{Anchor: ep.Anchor, Title: p.Title})
+			}
+			for _, v := range p.Verses {
+				ep.Verses = append(ep.Verses, epubVerse{Anchor: fmt.Sprintf("c%dv%d", c.Nb, v.Nb), Nb: v.Nb, HTML: string(v.HTML)})
+			}
+			chapter.Paragraphs = append(chapter.Paragraphs, ep)
+		}
+		data.Chapters = append(data.Chapters, chapter)
+	}
+	return data
+}
+
+// writeEPUB writes the enhanced books as an EPUB 3 with one xhtml file per
+// book and a navigation document down to the section titles.
+func writeEPUB(file, title string, modified time.Time) {
+	data := epubData{
+		Lang:     displayLanguage(),
+		ID:       fmt.Sprintf("urn:sha1:%x", sha1.Sum([]byte(title))),
+		Title:    title,
+		Modified: modified.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	for _, book := range enhanced {
+		data.Books = append(data.Books, epubBookContent(book))
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+
+	// the mimetype must come first and stay uncompressed
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		panic(err)
+	}
+	io.WriteString(w, "application/epub+zip")
+
+	writeEntry := func(name string, render func(w io.Writer) error) {
+		w, err := zw.Create(name)
+		if err != nil {
+			panic(err)
+		}
+		if err := render(w); err != nil {
+			panic(fmt.Errorf("failed to write %s: %w", name, err))
+		}
+	}
+	writeString := func(s string) func(w io.Writer) error {
+		return func(w io.Writer) error {
+			_, err := io.WriteString(w, s)
+			return err
+		}
+	}
+	writeEntry("META-INF/container.xml", writeString(epubContainer))
+	writeEntry("OEBPS/style.css", writeString(epubStyle))
+	writeEntry("OEBPS/content.opf", func(w io.Writer) error { return epubPackage.Execute(w, data) })
+	writeEntry("OEBPS/nav.xhtml", func(w io.Writer) error { return epubNav.Execute(w, data) })
+	for _, book := range data.Books {
+		book := book
+		writeEntry("OEBPS/"+book.File, func(w io.Writer) error { return epubBook.Execute(w, book) })
+	}
+	if err := zw.Close(); err != nil {
+		panic(err)
+	}
+	fmt.Printf("wrote %v books to %s!\n", len(data.Books), file)
+}
+
+func epubCommand(args []string) {
+	flags := flag.NewFlagSet("epub", flag.ExitOnError)
+	out := flags.String("out", epubPath, "output file")
+	title := flags.String("title", "The Holy Bible, King James Version", "book title")
+	modified := flags.String("modified", epubModified, "modification date of the book, e.g. 2024-01-01T00:00:00Z")
+	lang := localeFlag(flags)
+	_ = flags.Parse(args)
+	useLocale(*lang)
+	date, err := time.Parse(time.RFC3339, *modified)
+	if err != nil {
+		log.Fatalf("invalid modification date: %s", *modified)
+	}
+
+	loadEnhancedData()
+	writeEPUB(*out, *title, date)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readZipEntry(t *testing.T, file, name string) string {
+	r, err := zip.OpenReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rc.Close()
+		content, err := ioutil.ReadAll(rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+	t.Fatalf("%s has no %s", file, name)
+	return ""
+}
+
+func TestWriteEPUB(t *testing.T) {
+	useBooks(t, roundTripBooks())
+	useDisplayLocale(t, "fr")
+	dir := t.TempDir()
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	first, second := filepath.Join(dir, "first.epub"), filepath.Join(dir, "second.epub")
+	writeEPUB(first, "La Bible", modified)
+	writeEPUB(second, "La Bible", modified)
+
+	opf := readZipEntry(t, first, "OEBPS/content.opf")
+	for _, want := range []string{`xml:lang="fr"`, `<dc:language>fr</dc:language>`, `<meta property="dcterms:modified">2024-05-01T12:00:00Z</meta>`} {
+		if !strings.Contains(opf, want) {
+			t.Errorf("content.opf doesn't hold %s", want)
+		}
+	}
+	book := readZipEntry(t, first, "OEBPS/01-Genesis.xhtml")
+	if !strings.Contains(book, `xml:lang="fr" lang="fr"`) || !strings.Contains(book, "<h1>Genèse</h1>") {
+		t.Errorf("Genesis isn't written in the fr locale:\n%s", book)
+	}
+
+	a, err := ioutil.ReadFile(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a, b) {
+		t.Error("the same books gave two different files")
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+var flatPath = "./flat"
+
+// flatSection is a run of verses sharing a section title, within a chapter.
+type flatSection struct {
+	info  *BookInfo
+	title string
+	start VerseID
+	end   VerseID
+	count int
+}
+
+func flatSections() []*flatSection {
+	var sections []*flatSection
+	for _, book := range enhanced {
+		info := mustBookInfo(book.Title)
+		for _, c := range book.Chapters {
+			var current *flatSection
+			for _, v := range c.Verses {
+				if current == nil || v.Title != "" {
+					current = &flatSection{info: info, title: v.Title, start: v.ID}
+					sections = append(sections, current)
+				}
+				current.end = v.ID
+				current.count++
+			}
+		}
+	}
+	return sections
+}
+
+func writeFlatFile(file string, comma rune, header []string, rows [][]string) {
+	f, err := os.Create(file)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	w.Comma = comma
+	if err := w.Write(header); err != nil {
+		panic(err)
+	}
+	if err := w.WriteAll(rows); err != nil {
+		panic(fmt.Errorf("failed to write %s: %w", file, err))
+	}
+	fmt.Println("wrote file: ", file)
+}
+
+// writeFlat writes a verses table, one row per verse, and a sections table as csv or tsv.
+func writeFlat(dir, format string) {
+	comma := ','
+	if format == "tsv" {
+		comma = '\t'
+	}
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		panic(err)
+	}
+
+	var verses [][]string
+	for _, book := range enhanced {
+		info := mustBookInfo(book.Title)
+		for _, c := range book.Chapters {
+			section := ""
+			for _, v := range c.Verses {
+				if v.Title != "" {
+					section = v.Title
+				}
+				verses = append(verses, []string{
+					strconv.Itoa(info.Nb),
+					info.DisplayName(),
+					strconv.Itoa(c.Nb),
+					strconv.Itoa(v.Nb),
+					strconv.Itoa(int(v.ID)),
+					v.OSIS,
+					info.Testament.String(),
+					section,
+					v.Subtitle,
+					v.Text,
+				})
+			}
+		}
+	}
+	writeFlatFile(filepath.Join(dir, "verses."+format), comma,
+		[]string{"book_order", "book", "chapter", "verse", "verse_id", "osis", "testament", "section", "subtitle", "text"},
+		verses)
+
+	var sections [][]string
+	for i, s := range flatSections() {
+		sections = append(sections, []string{
+			strconv.Itoa(i + 1),
+			strconv.Itoa(s.info.Nb),
+			s.info.DisplayName(),
+			strconv.Itoa(s.start.Chapter()),
+			strconv.Itoa(int(s.start)),
+			strconv.Itoa(int(s.end)),
+			VerseRange{Start: s.start, End: s.end}.String(),
+			strconv.Itoa(s.count),
+			s.title,
+		})
+	}
+	writeFlatFile(filepath.Join(dir, "sections."+format), comma,
+		[]string{"section_id", "book_order", "book", "chapter", "start_verse_id", "end_verse_id", "reference", "verses", "title"},
+		sections)
+}
+
+func flatCommand(args []string) {
+	flags := flag.NewFlagSet("flat", flag.ExitOnError)
+	out := flags.String("out", flatPath, "output directory")
+	format := flags.String("format", "csv", "csv or tsv")
+	lang := localeFlag(flags)
+	_ = flags.Parse(args)
+	useLocale(*lang)
+	if *format != "csv" && *format != "tsv" {
+		log.Fatalf("unknown format: %s, expecting csv or tsv", *format)
+	}
+
+	loadEnhancedData()
+	writeFlat(*out, *format)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func flatBooks() []*BookEnhanced {
+	return []*BookEnhanced{{Title: "Ruth", Chapters: []*ChapterEnhanced{
+		{Nb: 1, Verses: []*VerseEnhanced{
+			roundTripVerse("Ruth", 1, 1, "Naomi and Ruth", "Now it came to pass in the days when the judges ruled."),
+			roundTripVerse("Ruth", 1, 2, "", "And the name of the man was Elimelech."),
+			roundTripVerse("Ruth", 1, 3, "Orpah and Ruth", "And Elimelech Naomi’s husband died."),
+		}},
+		{Nb: 2, Verses: []*VerseEnhanced{
+			roundTripVerse("Ruth", 2, 1, "", "And Naomi had a kinsman of her husband’s."),
+		}},
+	}}}
+}
+
+func TestFlatSections(t *testing.T) {
+	useBooks(t, flatBooks())
+	tests := []struct {
+		title      string
+		start, end VerseID
+		count      int
+	}{
+		{"Naomi and Ruth", newVerseID(8, 1, 1), newVerseID(8, 1, 2), 2},
+		{"Orpah and Ruth", newVerseID(8, 1, 3), newVerseID(8, 1, 3), 1},
+		{"", newVerseID(8, 2, 1), newVerseID(8, 2, 1), 1},
+	}
+	sections := flatSections()
+	if len(sections) != len(tests) {
+		t.Fatalf("%d sections, expecting %d", len(sections), len(tests))
+	}
+	for i, test := range tests {
+		s := sections[i]
+		if s.title != test.title || s.start != test.start || s.end != test.end || s.count != test.count {
+			t.Errorf("section %d is %q %s-%s of %d verses, expecting %q %s-%s of %d verses",
+				i+1, s.title, s.start, s.end, s.count, test.title, test.start, test.end, test.count)
+		}
+	}
+}
+
+func TestWriteFlat(t *testing.T) {
+	useDisplayLocale(t, "en")
+	useBooks(t, flatBooks())
+	tests := []struct {
+		format string
+		comma  rune
+	}{
+		{"csv", ','},
+		{"tsv", '\t'},
+	}
+	for _, test := range tests {
+		dir := t.TempDir()
+		writeFlat(dir, test.format)
+		verses := readFlatFile(t, filepath.Join(dir, "verses."+test.format), test.comma)
+		want := []string{"8", "Ruth", "1", "3", "8001003", "Ruth.1.3", "OT", "Orpah and Ruth", "", "And Elimelech Naomi’s husband died."}
+		if len(verses) != 5 || !reflect.DeepEqual(verses[3], want) {
+			t.Errorf("%s verses are %q, expecting row 3 to be %q", test.format, verses, want)
+		}
+		sections := readFlatFile(t, filepath.Join(dir, "sections."+test.format), test.comma)
+		want = []string{"1", "8", "Ruth", "1", "8001001", "8001002", "Ruth 1:1-2", "2", "Naomi and Ruth"}
+		if len(sections) != 4 || !reflect.DeepEqual(sections[1], want) {
+			t.Errorf("%s sections are %q, expecting row 1 to be %q", test.format, sections, want)
+		}
+	}
+}
+
+func readFlatFile(t *testing.T, file string, comma rune) [][]string {
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.Comma = comma
+	rows, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
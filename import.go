@@ -0,0 +1,710 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var importPath = "./json/imported"
+
+var importers = map[string]func(file string) ([]*BookEnhanced, error){
+	"osis":     importOSIS,
+	"usfm":     importUSFM,
+	"zefania":  importZefania,
+	"opensong": importOpenSong,
+}
+
+// bibleImport collects the books of an imported file, the verse text is
+// appended piece by piece with its whitespace collapsed so the span offsets
+// stay valid.
+type bibleImport struct {
+	books   []*BookEnhanced
+	info    *BookInfo
+	chapter *ChapterEnhanced
+	verse   *VerseEnhanced
+	title   string
+	stanza  string
+	par     bool
+	open    []Span
+}
+
+func (imp *bibleImport) startBook(info *BookInfo) {
+	imp.endVerse()
+	imp.info = info
+	imp.chapter = nil
+	imp.books = append(imp.books, &BookEnhanced{Title: info.Name})
+}
+
+func (imp *bibleImport) startChapter(nb int) {
+	imp.endVerse()
+	if imp.info == nil {
+		return
+	}
+	book := imp.books[len(imp.books)-1]
+	imp.chapter = &ChapterEnhanced{Nb: nb}
+	book.Chapters = append(book.Chapters, imp.chapter)
+}
+
+func (imp *bibleImport) startVerse(nb int) {
+	imp.endVerse()
+	if imp.chapter == nil {
+		return
+	}
+	id := newVerseID(imp.info.Nb, imp.chapter.Nb, nb)
+	imp.verse = &VerseEnhanced{ID: id, OSIS: id.OSIS(), Nb: nb, Title: imp.title, StanzaHeading: imp.stanza, Paragraph: imp.par}
+	imp.chapter.Verses = append(imp.chapter.Verses, imp.verse)
+	imp.title, imp.stanza, imp.par = "", "", false
+	for i := range imp.open {
+		imp.open[i].Start = 0
+	}
+}
+
+// endVerse trims the verse and closes the spans still open, they are
+// reopened at the start of the next verse.
+func (imp *bibleImport) endVerse() {
+	v := imp.verse
+	if v == nil {
+		return
+	}
+	for _, s := range imp.open {
+		addSpan(v, s.Style, s.Start, len(v.Text))
+	}
+	v.Text = strings.TrimRight(v.Text, " ")
+	spans := v.Spans[:0]
+	for _, s := range v.Spans {
+		if s.End > len(v.Text) {
+			s.End = len(v.Text)
+		}
+		if s.Start < s.End {
+			spans = append(spans, s)
+		}
+	}
+	v.Spans = spans
+	imp.verse = nil
+}
+
+func (imp *bibleImport) text(s string) {
+	v := imp.verse
+	if v == nil {
+		return
+	}
+	var b strings.Builder
+	b.WriteString(v.Text)
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			if b.Len() > 0 && !strings.HasSuffix(b.String(), " ") {
+				b.WriteByte(' ')
+			}
+			continue
+		}
+		b.WriteRune(r)
+	}
+	v.Text = b.String()
+}
+
+func (imp *bibleImport) heading(title string) {
+	imp.endVerse()
+	title = strings.Join(strings.Fields(title), " ")
+	if imp.title != "" && title != "" {
+		title = imp.title + " " + title
+	}
+	imp.title = title
+}
+
+func (imp *bibleImport) superscription(text string) {
+	if imp.chapter != nil {
+		imp.chapter.Superscription = strings.Join(strings.Fields(text), " ")
+	}
+}
+
+func (imp *bibleImport) introduction(text string) {
+	if len(imp.books) == 0 || text == "" {
+		return
+	}
+	book := imp.books[len(imp.books)-1]
+	if book.Introduction != "" {
+		book.Introduction += "\n\n"
+	}
+	book.Introduction += text
+}
+
+func (imp *bibleImport) summary(text string) {
+	if imp.chapter != nil {
+		imp.chapter.Summary = text
+	}
+}
+
+// paragraph marks the next verse as starting a paragraph.
+func (imp *bibleImport) paragraph() {
+	imp.par = true
+}
+
+func (imp *bibleImport) stanzaHeading(heading string) {
+	imp.endVerse()
+	imp.stanza = strings.Join(strings.Fields(heading), " ")
+}
+
+func (imp *bibleImport) openStyle(style string) {
+	start := 0
+	if imp.verse != nil {
+		start = len(imp.verse.Text)
+	}
+	imp.open = append(imp.open, Span{Start: start, Style: style})
+}
+
+func (imp *bibleImport) closeStyle(style string) {
+	for i := len(imp.open) - 1; i >= 0; i-- {
+		if imp.open[i].Style != style {
+			continue
+		}
+		if v := imp.verse; v != nil {
+			addSpan(v, style, imp.open[i].Start, len(strings.TrimRight(v.Text, " ")))
+		}
+		imp.open = append(imp.open[:i], imp.open[i+1:]...)
+		return
+	}
+}
+
+func (imp *bibleImport) result() []*BookEnhanced {
+	imp.endVerse()
+	return imp.books
+}
+
+// importZefania reads a Zefania XML bible, the books being numbered in the order of Books.json.
+func importZefania(file string) ([]*BookEnhanced, error) {
+	bible := new(zefaniaBible)
+	if err := readXMLFile(file, bible); err != nil {
+		return nil, err
+	}
+	names, err := readBookNames()
+	if err != nil {
+		return nil, err
+	}
+	imp := new(bibleImport)
+	for _, zb := range bible.Books {
+		if zb.Nb < 1 || zb.Nb > len(names) {
+			logError(fmt.Errorf("%s: skipping book with invalid number %d", file, zb.Nb))
+			continue
+		}
+		imp.startBook(mustBookInfo(names[zb.Nb-1]))
+		for _, zc := range zb.Chapters {
+			imp.startChapter(zc.Nb)
+			for _, item := range zc.Items {
+				switch item.XMLName.Local {
+				case "CAPTION":
+					imp.heading(xmlText(item.Content))
+				case "VERS":
+					imp.startVerse(item.Nb)
+					if err := zefaniaVerseContent(imp, item.Content); err != nil {
+						return nil, fmt.Errorf("%s: %s %d:%d: %w", file, imp.info.Name, zc.Nb, item.Nb, err)
+					}
+				}
+			}
+		}
+	}
+	return imp.result(), nil
+}
+
+// importOpenSong reads an OpenSong bible, the books being found by their
+// English or localized name. The format has no titles nor styles.
+func importOpenSong(file string) ([]*BookEnhanced, error) {
+	bible := new(openSongBible)
+	if err := readXMLFile(file, bible); err != nil {
+		return nil, err
+	}
+	imp := new(bibleImport)
+	for _, ob := range bible.Books {
+		info := bookInfo(ob.Name)
+		if info == nil {
+			logError(fmt.Errorf("%s: skipping unknown book %s", file, ob.Name))
+			continue
+		}
+		imp.startBook(info)
+		for _, oc := range ob.Chapters {
+			imp.startChapter(oc.Nb)
+			for _, ov := range oc.Verses {
+				imp.startVerse(ov.Nb)
+				imp.text(ov.Text)
+			}
+		}
+	}
+	return imp.result(), nil
+}
+
+// zefaniaVerseContent reads the inner xml of a VERS, STYLE elements become
+// spans and notes are left out.
+func zefaniaVerseContent(imp *bibleImport, content string) error {
+	dec := xml.NewDecoder(strings.NewReader("<VERS>" + content + "</VERS>"))
+	var styles []string
+	skip := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			style := ""
+			switch t.Name.Local {
+			case "NOTE", "BR":
+				skip++
+			case "STYLE":
+				if style = zefaniaStyle(t.Attr); style != "" {
+					imp.openStyle(style)
+				}
+			}
+			styles = append(styles, style)
+		case xml.EndElement:
+			style := styles[len(styles)-1]
+			styles = styles[:len(styles)-1]
+			switch t.Name.Local {
+			case "NOTE", "BR":
+				skip--
+			case "STYLE":
+				if style != "" {
+					imp.closeStyle(style)
+				}
+			}
+		case xml.CharData:
+			if skip == 0 {
+				imp.text(string(t))
+			}
+		}
+	}
+}
+
+// zefaniaStyle maps the css or fs attribute of a STYLE element to a span style.
+func zefaniaStyle(attrs []xml.Attr) string {
+	for _, attr := range attrs {
+		value := strings.ToLower(strings.ReplaceAll(attr.Value, " ", ""))
+		if attr.Name.Local == "fs" && value == "italic" {
+			return SpanSupplied
+		}
+		for style, css := range zefaniaStyles {
+			if strings.Contains(value, strings.ToLower(css)) {
+				return style
+			}
+		}
+	}
+	return ""
+}
+
+// osisVerse marks the verse containers in the element stack of importOSIS.
+const osisVerse = "verse"
+
+// importOSIS reads an OSIS XML document, verses can either be containers or
+// sID/eID milestones.
+func importOSIS(file string) ([]*BookEnhanced, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	imp := new(bibleImport)
+	dec := xml.NewDecoder(f)
+	var elements []string
+	var titleText strings.Builder
+	inTitle, psalmTitle, skip := false, false, 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			attrs := make(map[string]string)
+			for _, attr := range t.Attr {
+				attrs[attr.Name.Local] = attr.Value
+			}
+			style := ""
+			switch t.Name.Local {
+			case "div":
+				if attrs["type"] == "book" && attrs["osisID"] != "" {
+					info := bookInfo(attrs["osisID"])
+					if info == nil {
+						return nil, fmt.Errorf("%s: unknown book %s", file, attrs["osisID"])
+					}
+					imp.startBook(info)
+				}
+			case "chapter":
+				if id := attrs["osisID"]; id != "" && attrs["eID"] == "" {
+					dot := strings.Index(id, ".")
+					if dot == -1 {
+						return nil, fmt.Errorf("%s: invalid chapter %s", file, id)
+					}
+					nb, err := strconv.Atoi(id[strings.LastIndex(id, ".")+1:])
+					if err != nil {
+						return nil, fmt.Errorf("%s: invalid chapter %s", file, id)
+					}
+					if info := bookInfo(id[:dot]); info != nil && info != imp.info {
+						imp.startBook(info)
+					}
+					imp.startChapter(nb)
+				}
+			case "verse":
+				if id := attrs["osisID"]; id != "" && attrs["eID"] == "" {
+					// a verse may cover several ids, "Gen.1.1 Gen.1.2"
+					vid, err := splitOSISID(strings.Fields(id)[0])
+					if err != nil {
+						return nil, fmt.Errorf("%s: %w", file, err)
+					}
+					if imp.chapter == nil || imp.chapter.Nb != vid.Chapter() || imp.info.Nb != vid.Book() {
+						if imp.info == nil || imp.info.Nb != vid.Book() {
+							imp.startBook(vid.BookInfo())
+						}
+						imp.startChapter(vid.Chapter())
+					}
+					imp.startVerse(vid.Verse())
+					if attrs["sID"] == "" {
+						style = osisVerse
+					}
+				} else if attrs["eID"] != "" {
+					imp.endVerse()
+				}
+			case "title":
+				inTitle, psalmTitle = true, attrs["type"] == "psalm" || attrs["canonical"] == "true"
+				titleText.Reset()
+			case "note", "rdg":
+				skip++
+			case "p", "lg":
+				imp.paragraph()
+			case "transChange":
+				if attrs["type"] == "added" {
+					style = SpanSupplied
+				}
+			case "q":
+				if attrs["who"] == "Jesus" {
+					style = SpanWordsOfJesus
+				}
+			}
+			if style != "" && style != osisVerse {
+				imp.openStyle(style)
+			}
+			elements = append(elements, style)
+		case xml.EndElement:
+			style := elements[len(elements)-1]
+			elements = elements[:len(elements)-1]
+			switch t.Name.Local {
+			case "title":
+				if psalmTitle && imp.verse == nil {
+					imp.superscription(titleText.String())
+				} else if imp.verse == nil && imp.chapter != nil {
+					imp.heading(titleText.String())
+				}
+				inTitle = false
+			case "note", "rdg":
+				skip--
+			}
+			if style == osisVerse {
+				imp.endVerse()
+			} else if style != "" {
+				imp.closeStyle(style)
+			}
+		case xml.CharData:
+			if skip > 0 {
+				continue
+			}
+			if inTitle {
+				titleText.Write(t)
+			} else {
+				imp.text(string(t))
+			}
+		}
+	}
+	return imp.result(), nil
+}
+
+var usfmLine = regexp.MustCompile(`^\s*\\([a-z]+[0-9]*)\s?`)
+var usfmVerse = regexp.MustCompile(`^\s*(\d+)(?:-\d+)?\s?`)
+var usfmMarker = regexp.MustCompile(`\\(\+?[a-z]+[0-9]*)(\*?)`)
+
+var usfmStyles = map[string]string{
+	"wj":  SpanWordsOfJesus,
+	"add": SpanSupplied,
+}
+
+// usfmNotes are the markers whose content, up to their closing marker, isn't verse text.
+var usfmNotes = map[string]bool{"f": true, "fe": true, "x": true, "fig": true}
+
+// importUSFM reads one or more USFM files, the book of each being given by its \id marker.
+func importUSFM(file string) ([]*BookEnhanced, error) {
+	files := []string{file}
+	if stat, err := os.Stat(file); err == nil && stat.IsDir() {
+		entries, err := ioutil.ReadDir(file)
+		if err != nil {
+			return nil, err
+		}
+		files = nil
+		for _, entry := range entries {
+			switch strings.ToLower(filepath.Ext(entry.Name())) {
+			case ".usfm", ".sfm":
+				if !entry.IsDir() {
+					files = append(files, filepath.Join(file, entry.Name()))
+				}
+			}
+		}
+	}
+	imp := new(bibleImport)
+	for _, name := range files {
+		if err := importUSFMFile(imp, name); err != nil {
+			return nil, err
+		}
+	}
+	return imp.result(), nil
+}
+
+func importUSFMFile(imp *bibleImport, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	skip := ""
+	lineNb := 0
+	for scanner.Scan() {
+		lineNb++
+		line := scanner.Text()
+		marker, rest := "", line
+		if m := usfmLine.FindStringSubmatch(line); m != nil {
+			marker, rest = m[1], line[len(m[0]):]
+		}
+		switch marker {
+		case "id":
+			code := strings.Fields(rest + " ")[0]
+			info := bookInfo(code)
+			if info == nil {
+				return fmt.Errorf("%s:%d: unknown book %s", file, lineNb, code)
+			}
+			imp.startBook(info)
+			continue
+		case "c":
+			nb, err := strconv.Atoi(strings.TrimSpace(rest))
+			if err != nil {
+				return fmt.Errorf("%s:%d: invalid chapter %s", file, lineNb, rest)
+			}
+			imp.startChapter(nb)
+			continue
+		case "s", "s1", "s2", "s3", "ms", "ms1", "ms2":
+			imp.heading(usfmPlain(rest))
+			continue
+		case "qa":
+			imp.stanzaHeading(usfmPlain(rest))
+			continue
+		case "d":
+			imp.superscription(usfmPlain(rest))
+			continue
+		case "ip":
+			imp.introduction(usfmPlain(rest))
+			continue
+		case "cd":
+			imp.summary(usfmPlain(rest))
+			continue
+		case "v":
+			rest = line
+		case "p", "m", "pi", "pi1", "pi2", "nb", "b", "q", "q1", "q2", "q3", "li", "li1", "li2":
+			if marker == "p" || marker == "m" || marker == "b" || strings.HasPrefix(marker, "pi") {
+				imp.paragraph()
+			}
+		case "":
+		default:
+			// \h, \toc, \mt and the other headers
+			if imp.verse == nil || imp.chapter == nil {
+				continue
+			}
+		}
+		if err := usfmText(imp, rest, &skip); err != nil {
+			return fmt.Errorf("%s:%d: %w", file, lineNb, err)
+		}
+		imp.text(" ")
+	}
+	return scanner.Err()
+}
+
+// usfmText adds a line of verse text, handling the character markers and
+// the verses starting within the line.
+func usfmText(imp *bibleImport, line string, skip *string) error {
+	last := 0
+	for _, m := range usfmMarker.FindAllStringSubmatchIndex(line, -1) {
+		if m[0] < last {
+			continue
+		}
+		if *skip == "" {
+			imp.text(usfmWord(line[last:m[0]]))
+		}
+		last = m[1]
+		name := strings.TrimPrefix(line[m[2]:m[3]], "+")
+		closing := m[5] > m[4]
+		if name == "v" && !closing {
+			// keep the first verse of a range like 1-2
+			nb := usfmVerse.FindStringSubmatch(line[last:])
+			if nb == nil {
+				return fmt.Errorf("invalid verse %s", line[m[0]:])
+			}
+			verse, _ := strconv.Atoi(nb[1])
+			imp.startVerse(verse)
+			last += len(nb[0])
+			*skip = ""
+			continue
+		}
+		if *skip != "" {
+			if closing && name == *skip {
+				*skip = ""
+			}
+			continue
+		}
+		if usfmNotes[name] && !closing {
+			*skip = name
+			continue
+		}
+		if style, ok := usfmStyles[name]; ok {
+			if closing {
+				imp.closeStyle(style)
+			} else {
+				imp.openStyle(style)
+			}
+		}
+	}
+	if *skip == "" {
+		imp.text(usfmWord(line[last:]))
+	}
+	return nil
+}
+
+// usfmWord drops the attributes of a \w word|strong="H1"\w* marker.
+func usfmWord(text string) string {
+	if i := strings.Index(text, "|"); i >= 0 {
+		return text[:i]
+	}
+	return text
+}
+
+func usfmPlain(text string) string {
+	return strings.Join(strings.Fields(usfmWord(usfmMarker.ReplaceAllString(text, ""))), " ")
+}
+
+// verifyImported reports the chapters and verses of the imported books which
+// don't match the catalogue, other translations may differ in versification.
+func verifyImported(books []*BookEnhanced) int {
+	differences := 0
+	for _, book := range books {
+		info := mustBookInfo(book.Title)
+		if len(book.Chapters) != info.Chapters() {
+			logError(fmt.Errorf("import: %s has %d chapters, expecting %d", info.Name, len(book.Chapters), info.Chapters()))
+			differences++
+		}
+		for _, c := range book.Chapters {
+			if len(c.Verses) != info.VerseCount(c.Nb) {
+				logError(fmt.Errorf("import: %s %d has %d verses, expecting %d", info.Name, c.Nb, len(c.Verses), info.VerseCount(c.Nb)))
+				differences++
+			}
+			for _, v := range c.Verses {
+				if strings.TrimSpace(v.Text) == "" {
+					logError(fmt.Errorf("import: %s is empty", v.ID))
+					differences++
+				}
+			}
+		}
+	}
+	return differences
+}
+
+func importCommand(args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	format := flags.String("format", "", "osis, usfm, zefania or opensong, guessed from the file extension when empty")
+	out := flags.String("out", importPath, "output directory for the enhanced json")
+	code := flags.String("code", "", "translation code, writes to the translations directory when set")
+	name := flags.String("name", "", "translation name")
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		log.Fatal("usage: import [-format osis|usfm|zefania|opensong] [-out dir | -code web [-name name]] <file or usfm directory>")
+	}
+	file := flags.Arg(0)
+	if *format == "" {
+		*format = guessImportFormat(file)
+	}
+	importer, ok := importers[*format]
+	if !ok {
+		log.Fatalf("unknown format: %s, expecting osis, usfm, zefania or opensong", *format)
+	}
+
+	books, err := importer(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("imported %v books from %s\n", len(books), file)
+	if differences := verifyImported(books); differences > 0 {
+		fmt.Printf("found %v differences with the catalogue, see %s\n", differences, logFile)
+	}
+	enhanced = books
+	enhancedVerses = make(map[VerseID]*VerseEnhanced)
+	resetWordIndexes()
+	for _, book := range books {
+		for _, c := range book.Chapters {
+			for _, v := range c.Verses {
+				enhancedVerses[v.ID] = v
+			}
+		}
+	}
+	enhancedPath = *out
+	if *code != "" {
+		enhancedPath = filepath.Join(translationsPath, strings.ToLower(*code))
+	}
+	writeEnhancedBooks(os.Stdout)
+	if *name != "" {
+		bytes, err := json.Marshal(translationInfo{Name: *name})
+		if err != nil {
+			panic(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(enhancedPath, "translation.json"), bytes, 0777); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func guessImportFormat(file string) string {
+	if stat, err := os.Stat(file); err == nil && stat.IsDir() {
+		return "usfm"
+	}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".usfm", ".sfm":
+		return "usfm"
+	case ".osis":
+		return "osis"
+	case ".xmm":
+		return "opensong"
+	}
+	head := make([]byte, 512)
+	if f, err := os.Open(file); err == nil {
+		n, _ := f.Read(head)
+		f.Close()
+		if strings.Contains(string(head[:n]), "<osis") {
+			return "osis"
+		}
+		if strings.Contains(string(head[:n]), "<bible>") {
+			return "opensong"
+		}
+	}
+	return "zefania"
+}
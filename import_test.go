@@ -0,0 +1,195 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// compareRoundTrip compares every verse of the imported books with the exported ones.
+func compareRoundTrip(t *testing.T, format string, want, got []*BookEnhanced, titles bool) {
+	imported := make(map[VerseID]*VerseEnhanced)
+	for _, book := range got {
+		for _, c := range book.Chapters {
+			for _, v := range c.Verses {
+				imported[v.ID] = v
+			}
+		}
+	}
+	count := 0
+	for _, book := range want {
+		for _, c := range book.Chapters {
+			for _, v := range c.Verses {
+				count++
+				iv, ok := imported[v.ID]
+				if !ok {
+					t.Errorf("%s: missing verse %s", format, v.ID)
+					continue
+				}
+				if iv.Text != v.Text {
+					t.Errorf("%s: verse %s is %q, expecting %q", format, v.ID, iv.Text, v.Text)
+				}
+				if titles && iv.Title != v.Title {
+					t.Errorf("%s: title of %s is %q, expecting %q", format, v.ID, iv.Title, v.Title)
+				}
+				if titles && len(v.Spans) > 0 && !reflect.DeepEqual(iv.Spans, v.Spans) {
+					t.Errorf("%s: spans of %s are %v, expecting %v", format, v.ID, iv.Spans, v.Spans)
+				}
+			}
+		}
+	}
+	if len(imported) != count {
+		t.Errorf("%s: imported %d verses, expecting %d", format, len(imported), count)
+	}
+}
+
+func TestZefaniaRoundTrip(t *testing.T) {
+	useBooks(t, roundTripBooks())
+	file := filepath.Join(t.TempDir(), "bible.xml")
+	writeXMLFile(file, zefaniaFromEnhanced("Round Trip"))
+	books, err := importZefania(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareRoundTrip(t, "zefania", enhanced, books, true)
+}
+
+func TestOpenSongRoundTrip(t *testing.T) {
+	useBooks(t, roundTripBooks())
+	file := filepath.Join(t.TempDir(), "bible.xml")
+	writeXMLFile(file, openSongFromEnhanced())
+	books, err := importOpenSong(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareRoundTrip(t, "opensong", enhanced, books, false)
+}
+
+func writeTestFile(t *testing.T, name, data string) string {
+	file := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestImportOSIS(t *testing.T) {
+	file := writeTestFile(t, "kjv.osis", `<osis><osisText>
+<div type="book" osisID="Ps"><chapter osisID="Ps.3">
+<title type="psalm" canonical="true">A Psalm of David, when he fled from Absalom his son.</title>
+<verse osisID="Ps.3.1">LORD, how are they increased that trouble me! many <transChange type="added">are</transChange> they that rise up against me.<note>a note</note></verse>
+</chapter></div>
+<div type="book" osisID="John"><chapter osisID="John.11">
+<title>Jesus the Resurrection</title>
+<verse sID="John.11.35" osisID="John.11.35"/>Jesus wept.<verse eID="John.11.35"/>
+<verse osisID="John.11.25">Jesus said unto her, <q who="Jesus">I am the resurrection, and the life</q>.</verse>
+</chapter></div>
+</osisText></osis>`)
+	books, err := importOSIS(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(books) != 2 || books[0].Title != "Psalms" || books[1].Title != "John" {
+		t.Fatalf("imported %v books, expecting Psalms and John", len(books))
+	}
+	psalm := books[0].Chapters[0]
+	if psalm.Superscription != "A Psalm of David, when he fled from Absalom his son." {
+		t.Errorf("Psalm 3 superscription is %q", psalm.Superscription)
+	}
+	tests := []struct {
+		v     *VerseEnhanced
+		text  string
+		title string
+		spans []Span
+	}{
+		{psalm.Verses[0], "LORD, how are they increased that trouble me! many are they that rise up against me.", "",
+			[]Span{{Start: 51, End: 54, Style: SpanSupplied}}},
+		{books[1].Chapters[0].Verses[0], "Jesus wept.", "Jesus the Resurrection", nil},
+		{books[1].Chapters[0].Verses[1], "Jesus said unto her, I am the resurrection, and the life.", "",
+			[]Span{{Start: 21, End: 56, Style: SpanWordsOfJesus}}},
+	}
+	for _, test := range tests {
+		if test.v.Text != test.text || test.v.Title != test.title || !reflect.DeepEqual(test.v.Spans, test.spans) {
+			t.Errorf("%s is %q titled %q with spans %v, expecting %q titled %q with spans %v",
+				test.v.ID, test.v.Text, test.v.Title, test.v.Spans, test.text, test.title, test.spans)
+		}
+	}
+}
+
+func TestImportOSISErrors(t *testing.T) {
+	for _, data := range []string{
+		`<osis><div type="book" osisID="Gen"><chapter osisID="Gen"></chapter></div></osis>`,
+		`<osis><div type="book" osisID="Gen"><chapter osisID="Gen.one"></chapter></div></osis>`,
+		`<osis><div type="book" osisID="Hez"></div></osis>`,
+		`<osis><div type="book" osisID="Gen"><chapter osisID="Gen.1"><verse osisID="Gen.x.1">`,
+	} {
+		if _, err := importOSIS(writeTestFile(t, "bad.osis", data)); err == nil {
+			t.Errorf("importOSIS(%s) gave no error", data)
+		}
+	}
+}
+
+func TestImportUSFM(t *testing.T) {
+	file := writeTestFile(t, "19PSA.usfm", `\id PSA
+\h Psalms
+\ip The book of praises.
+\c 3
+\cd David's prayer when he fled.
+\d A Psalm of David, when he fled from Absalom his son.
+\q1
+\v 1 \w LORD|strong="H3068"\w*, how are they increased that trouble me!
+\q2 many \add are\add* they that rise up against me.\f + \ft a note\f*
+\v 2 Many \add there be\add* which say of my soul,
+`)
+	books, err := importUSFM(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(books) != 1 || len(books[0].Chapters) != 1 {
+		t.Fatalf("imported %v books, expecting Psalms 3", len(books))
+	}
+	book, psalm := books[0], books[0].Chapters[0]
+	if book.Introduction != "The book of praises." || psalm.Summary != "David's prayer when he fled." ||
+		psalm.Superscription != "A Psalm of David, when he fled from Absalom his son." {
+		t.Errorf("introduction %q, summary %q and superscription %q", book.Introduction, psalm.Summary, psalm.Superscription)
+	}
+	want := []*VerseEnhanced{
+		{Text: "LORD, how are they increased that trouble me! many are they that rise up against me.",
+			Spans: []Span{{Start: 51, End: 54, Style: SpanSupplied}}},
+		{Text: "Many there be which say of my soul,", Spans: []Span{{Start: 5, End: 13, Style: SpanSupplied}}},
+	}
+	if len(psalm.Verses) != len(want) {
+		t.Fatalf("imported %d verses, expecting %d", len(psalm.Verses), len(want))
+	}
+	for i, v := range psalm.Verses {
+		if v.Text != want[i].Text || !reflect.DeepEqual(v.Spans, want[i].Spans) {
+			t.Errorf("%s is %q with spans %v, expecting %q with spans %v", v.ID, v.Text, v.Spans, want[i].Text, want[i].Spans)
+		}
+	}
+}
+
+func TestGuessImportFormat(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		file, data, want string
+	}{
+		{"kjv.usfm", "", "usfm"},
+		{"kjv.osis", "", "osis"},
+		{"kjv.xml", `<?xml version="1.0"?><osis xmlns="http://www.bibletechnologies.net/2003/OSIS/namespace">`, "osis"},
+		{"kjv-opensong.xml", `<?xml version="1.0"?><bible><b n="Genesis">`, "opensong"},
+		{"kjv-zefania.xml", `<?xml version="1.0"?><XMLBIBLE biblename="KJV">`, "zefania"},
+	}
+	for _, test := range tests {
+		file := filepath.Join(dir, test.file)
+		if err := ioutil.WriteFile(file, []byte(test.data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if got := guessImportFormat(file); got != test.want {
+			t.Errorf("guessImportFormat(%s) = %s, expecting %s", test.file, got, test.want)
+		}
+	}
+	if got := guessImportFormat(dir); got != "usfm" {
+		t.Errorf("guessImportFormat of a directory = %s, expecting usfm", got)
+	}
+}
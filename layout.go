@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const pilcrow = "¶"
+
+// layoutPiece is a run of verse text from the page with the breaks found before it.
+type layoutPiece struct {
+	text      string
+	breaks    int
+	paragraph bool
+}
+
+// verseLine is one poetic line of a verse with the spans moved to its own offsets.
+type verseLine struct {
+	Text  string
+	Spans []Span
+}
+
+// Poetic reports whether the book is laid out in poetic lines.
+func (b *BookInfo) Poetic() bool {
+	return b.Genre == GenreWisdom || b.OSIS == "Lam"
+}
+
+// captureLayout records where the page starts paragraphs, stanzas and poetic lines.
+func captureLayout(book *Book, chapter *Chapter, document *goquery.Document) {
+	chap := getEnhancedChapter(book.Book, chapter.Chapter)
+	if chap == nil {
+		panic(fmt.Errorf("failed to find chapter: %s - %s", book.Book, chapter.Chapter))
+	}
+	poetic := mustBookInfo(book.Book).Poetic()
+
+	var pieces []layoutPiece
+	var pending layoutPiece
+	var walk func(s *goquery.Selection)
+	flush := func() {
+		if strings.TrimSpace(pending.text) != "" {
+			pieces = append(pieces, pending)
+			pending = layoutPiece{}
+		}
+	}
+	walk = func(s *goquery.Selection) {
+		s.Contents().Each(func(i int, node *goquery.Selection) {
+			switch name := goquery.NodeName(node); {
+			case name == "#text":
+				pending.text += node.Text()
+			case name == "br":
+				flush()
+				pending.breaks++
+			case name == "p":
+				flush()
+				pending.paragraph = true
+				walk(node)
+				flush()
+				pending.paragraph = true
+			case name == "strong" || name == "script" || name == "style" || node.HasClass("post__title_block"):
+				// section titles and page chrome aren't verse text
+			case node.HasClass("highlightDigit"):
+				// a verse number ends the previous run of text
+				flush()
+			default:
+				walk(node)
+			}
+		})
+	}
+	walk(document.Find(".post").First())
+	flush()
+
+	verse, offset := 0, 0
+	for _, piece := range pieces {
+		text := piece.text
+		if strings.Contains(text, pilcrow) {
+			piece.paragraph = true
+			text = strings.ReplaceAll(text, pilcrow, "")
+		}
+		text = normalizeText(text) // same typography as the verse text
+		first := true
+		for _, part := range spanVerseNumbers.Split(text, -1) {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			v, i := findInVerses(chap.Verses, part, verse, offset)
+			if v == -1 {
+				continue
+			}
+			verse, offset = v, i+len(part)
+			if !first {
+				// only the start of the piece follows the breaks
+				continue
+			}
+			first = false
+			ve := chap.Verses[v]
+			switch {
+			case i == 0 && piece.paragraph:
+				ve.Paragraph = true
+			case i == 0 && piece.breaks > 1 && poetic:
+				ve.Stanza = true
+			case i > 0 && (piece.paragraph || piece.breaks > 0):
+				ve.Lines = append(ve.Lines, i)
+			}
+		}
+	}
+	if len(chap.Verses) > 0 {
+		chap.Verses[0].Paragraph = true
+	}
+}
+
+// findInVerses looks for text from the given verse and offset, returning the
+// verse index and offset where it was found or -1.
+func findInVerses(verses []*VerseEnhanced, text string, verse, offset int) (int, int) {
+	for verse < len(verses) {
+		if i := indexWords(verses[verse].Text, text, offset); i != -1 {
+			return verse, i
+		}
+		verse++
+		offset = 0
+	}
+	return -1, -1
+}
+
+// verseLines splits the verse on its poetic line breaks.
+func verseLines(v *VerseEnhanced) []verseLine {
+	bounds := append([]int{0}, v.Lines...)
+	bounds = append(bounds, len(v.Text))
+	var lines []verseLine
+	for i := 0; i < len(bounds)-1; i++ {
+		start, end := bounds[i], bounds[i+1]
+		if start >= end || end > len(v.Text) {
+			continue
+		}
+		line := verseLine{Text: strings.TrimSpace(v.Text[start:end])}
+		trim := strings.Index(v.Text[start:end], line.Text)
+		for _, s := range v.Spans {
+			if s.End <= start || s.Start >= end {
+				continue
+			}
+			ls := Span{Start: s.Start - start - trim, End: s.End - start - trim, Style: s.Style}
+			if ls.Start < 0 {
+				ls.Start = 0
+			}
+			if ls.End > len(line.Text) {
+				ls.End = len(line.Text)
+			}
+			if ls.Start < ls.End {
+				line.Spans = append(line.Spans, ls)
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestCaptureLayout(t *testing.T) {
+	verses := []*VerseEnhanced{
+		roundTripVerse("Psalms", 23, 1, "", "The LORD is my shepherd; I shall not want."),
+		roundTripVerse("Psalms", 23, 2, "", "He maketh me to lie down in green pastures: he leadeth me beside the still waters."),
+		roundTripVerse("Psalms", 23, 3, "", "He restoreth my soul: he leadeth me in the paths of righteousness for his name’s sake."),
+	}
+	useBooks(t, []*BookEnhanced{{Title: "Psalms", Chapters: []*ChapterEnhanced{{Nb: 23, Verses: verses}}}})
+	page := `<div class="post"><p><span class="highlightDigit">1</span> The LORD is my shepherd;<br>I shall not want.</p>` +
+		`<p><span class="highlightDigit">2</span> He maketh me to lie down in green pastures:<br>he leadeth me beside the still waters.<br><br>` +
+		`<span class="highlightDigit">3</span> He restoreth my soul:<br>he leadeth me in the paths of righteousness for his name's&thinsp;sake.</p></div>`
+	document, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	captureLayout(&Book{Book: "Psalms"}, &Chapter{Chapter: "23"}, document)
+	tests := []struct {
+		paragraph, stanza bool
+		lines             []int
+	}{
+		{true, false, []int{25}},
+		{true, false, []int{44}},
+		{false, true, []int{22}},
+	}
+	for i, test := range tests {
+		v := verses[i]
+		if v.Paragraph != test.paragraph || v.Stanza != test.stanza || !reflect.DeepEqual(v.Lines, test.lines) {
+			t.Errorf("%s has paragraph %v, stanza %v and lines %v, expecting %v, %v and %v",
+				v.ID, v.Paragraph, v.Stanza, v.Lines, test.paragraph, test.stanza, test.lines)
+		}
+	}
+}
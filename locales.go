@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var localesPath = "./json/locales"
+
+// Locale holds the book names of a language, keyed by osis code.
+type Locale struct {
+	Code     string                 `json:"code"`
+	Language string                 `json:"language"`
+	Books    map[string]*LocaleBook `json:"books"`
+	lookup   map[string]*BookInfo
+}
+
+type LocaleBook struct {
+	Name         string   `json:"name"`
+	Abbreviation string   `json:"abbreviation"`
+	AltNames     []string `json:"altNames,omitempty"`
+}
+
+var locales map[string]*Locale
+
+// displayLocale is the language of the book names printed by the renderers, English when nil.
+var displayLocale *Locale
+
+// accents folds the accented letters so "Genese" finds "Genèse".
+var accents = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ä", "a", "ç", "c",
+	"è", "e", "é", "e", "ê", "e", "ë", "e", "ì", "i", "í", "i", "î", "i", "ï", "i",
+	"ñ", "n", "ò", "o", "ó", "o", "ô", "o", "ö", "o", "ù", "u", "ú", "u", "û", "u", "ü", "u",
+)
+
+func loadLocale(file string) (*Locale, error) {
+	bytes, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	locale := new(Locale)
+	if err := json.Unmarshal(bytes, locale); err != nil {
+		return nil, fmt.Errorf("failed to read locale %s: %w", file, err)
+	}
+	if locale.Code == "" {
+		locale.Code = strings.TrimSuffix(filepath.Base(file), ".json")
+	}
+	locale.lookup = make(map[string]*BookInfo)
+	for osis, book := range locale.Books {
+		info := bookInfo(osis)
+		if info == nil || info.OSIS != osis {
+			return nil, fmt.Errorf("locale %s: unknown osis code %s", locale.Code, osis)
+		}
+		keys := append([]string{book.Name, book.Abbreviation}, book.AltNames...)
+		for _, k := range keys {
+			if k != "" {
+				locale.lookup[bookKey(k)] = info
+			}
+		}
+	}
+	for _, info := range catalogue {
+		if _, ok := locale.Books[info.OSIS]; !ok {
+			logError(fmt.Errorf("locale %s: missing book %s", locale.Code, info.Name))
+		}
+	}
+	return locale, nil
+}
+
+// loadLocales reads the locale files once, on the first lookup needing them.
+func loadLocales() {
+	if locales != nil {
+		return
+	}
+	locales = make(map[string]*Locale)
+	files, _ := filepath.Glob(filepath.Join(localesPath, "*.json"))
+	for _, file := range files {
+		locale, err := loadLocale(file)
+		if err != nil {
+			logError(err)
+			continue
+		}
+		locales[locale.Code] = locale
+	}
+}
+
+func localeCodes() []string {
+	loadLocales()
+	var codes []string
+	for code := range locales {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// setDisplayLocale selects the language of the book names, "" or "en" for English.
+func setDisplayLocale(code string) error {
+	if code == "" || code == "en" {
+		displayLocale = nil
+		return nil
+	}
+	loadLocales()
+	locale, ok := locales[code]
+	if !ok {
+		return fmt.Errorf("unknown locale: %s, expecting en or one of %v", code, localeCodes())
+	}
+	displayLocale = locale
+	return nil
+}
+
+// localeBookInfo finds a book by a localized name, in the display locale first.
+func localeBookInfo(key string) *BookInfo {
+	if displayLocale != nil {
+		if info, ok := displayLocale.lookup[key]; ok {
+			return info
+		}
+	}
+	loadLocales()
+	for _, code := range localeCodes() {
+		if info, ok := locales[code].lookup[key]; ok {
+			return info
+		}
+	}
+	return nil
+}
+
+// displayLanguage is the language code of the display locale, for the lang attributes.
+func displayLanguage() string {
+	if displayLocale != nil {
+		return displayLocale.Code
+	}
+	return "en"
+}
+
+func localeFlag(flags *flag.FlagSet) *string {
+	return flags.String("lang", "", "language of the book names, e.g. fr")
+}
+
+func useLocale(code string) {
+	if err := setDisplayLocale(code); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// DisplayName is the name of the book in the display locale.
+func (b *BookInfo) DisplayName() string {
+	if displayLocale != nil {
+		if book, ok := displayLocale.Books[b.OSIS]; ok {
+			return book.Name
+		}
+	}
+	return b.Name
+}
+
+// DisplayAbbreviation is the abbreviation of the book in the display locale.
+func (b *BookInfo) DisplayAbbreviation() string {
+	if displayLocale != nil {
+		if book, ok := displayLocale.Books[b.OSIS]; ok && book.Abbreviation != "" {
+			return book.Abbreviation
+		}
+	}
+	return b.SBL
+}
@@ -0,0 +1,71 @@
+package main
+
+import "testing"
+
+func TestLocaleReferences(t *testing.T) {
+	tests := []struct {
+		locale, ref string
+		start, end  VerseID
+	}{
+		{"", "Genèse 1:1", newVerseID(1, 1, 1), newVerseID(1, 1, 1)},
+		{"", "Genese 1:1", newVerseID(1, 1, 1), newVerseID(1, 1, 1)},
+		{"", "Jean 3:16", newVerseID(43, 3, 16), newVerseID(43, 3, 16)},
+		{"", "1 Jean 1:9", newVerseID(62, 1, 9), newVerseID(62, 1, 9)},
+		{"", "Psaume 23", newVerseID(19, 23, 1), newVerseID(19, 23, 6)},
+		{"", "Johannes 3:16-17", newVerseID(43, 3, 16), newVerseID(43, 3, 17)},
+		{"", "1. Mose 1:1", newVerseID(1, 1, 1), newVerseID(1, 1, 1)},
+		{"", "Salmos 23:1", newVerseID(19, 23, 1), newVerseID(19, 23, 1)},
+		{"", "Génesis 1:1-Éxodo 1:1", newVerseID(1, 1, 1), newVerseID(2, 1, 1)},
+		{"fr", "Ap 1:1", newVerseID(66, 1, 1), newVerseID(66, 1, 1)},
+		{"de", "Offb 22:21", newVerseID(66, 22, 21), newVerseID(66, 22, 21)},
+		{"es", "Cnt 1:1", newVerseID(22, 1, 1), newVerseID(22, 1, 1)},
+		{"fr", "John 3:16", newVerseID(43, 3, 16), newVerseID(43, 3, 16)},
+	}
+	for _, test := range tests {
+		useDisplayLocale(t, test.locale)
+		r, err := parseReference(test.ref)
+		if err != nil {
+			t.Errorf("parseReference(%q) in %q: %v", test.ref, test.locale, err)
+			continue
+		}
+		if r.Start != test.start || r.End != test.end {
+			t.Errorf("parseReference(%q) in %q = %s-%s, expecting %s-%s", test.ref, test.locale, r.Start, r.End, test.start, test.end)
+		}
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	tests := []struct {
+		locale, book, name, abbreviation string
+	}{
+		{"", "John", "John", "John"},
+		{"en", "1 Sam", "1 Samuel", "1 Sam"},
+		{"fr", "Gen", "Genèse", "Gn"},
+		{"fr", "1 John", "1 Jean", "1 Jn"},
+		{"de", "Psalms", "Psalmen", "Ps"},
+		{"de", "Revelation", "Offenbarung", "Offb"},
+		{"es", "Song of Solomon", "Cantares", "Cnt"},
+	}
+	for _, test := range tests {
+		useDisplayLocale(t, test.locale)
+		info := mustBookInfo(test.book)
+		if info.DisplayName() != test.name || info.DisplayAbbreviation() != test.abbreviation {
+			t.Errorf("%s in %q is %s (%s), expecting %s (%s)", test.book, test.locale,
+				info.DisplayName(), info.DisplayAbbreviation(), test.name, test.abbreviation)
+		}
+	}
+}
+
+func TestSetDisplayLocale(t *testing.T) {
+	useDisplayLocale(t, "")
+	if err := setDisplayLocale("xx"); err == nil {
+		t.Error("setDisplayLocale accepted an unknown locale")
+	}
+	if codes := localeCodes(); len(codes) != 3 || codes[0] != "de" || codes[1] != "es" || codes[2] != "fr" {
+		t.Errorf("locales are %v, expecting [de es fr]", codes)
+	}
+	file := writeTestFile(t, "xx.json", `{"books": {"Hezekiah": {"name": "Ézéchias"}}}`)
+	if _, err := loadLocale(file); err == nil {
+		t.Error("loadLocale accepted an unknown osis code")
+	}
+}
@@ -4,11 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"path"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -21,7 +21,7 @@ import (
 var fetchFrom = "https://www.kjvbibles.com/blogs"
 var initial []*Book
 var enhanced []*BookEnhanced
-var enhancedVerses = make(map[string]map[int]map[int]*VerseEnhanced)
+var enhancedVerses = make(map[VerseID]*VerseEnhanced)
 var urlExceptions = map[string]string{
 	"1-samuel-chapter-16":    "1-samuel-chapter-166",
 	"deuteronomy-chapter-18": "deuteronomy-chapter-19", // has chapter 18 twice
@@ -49,7 +49,7 @@ var verseTitlesExceptions = map[string]map[string]map[string]string{
 		"24": {
 			"1":  "Elkanah and His Wives Go to Shiloh Every Year to Worship",
 			"9":  "Hannah Prays for a Child",
-			"19": "Hannah, Having Given Birth to Samuel, Stays at Home till he is Weaned",
+			"19": "Hannah, Having Given Birth to Samuel, Stays at Home till He Is Weaned",
 		},
 	},
 	"1 Thessalonians": {
@@ -82,28 +82,28 @@ var verseTitlesExceptions = map[string]map[string]map[string]string{
 	},
 	"2 John": {
 		"1": {
-			"1": "Living in the truth",
+			"1": "Living in the Truth",
 			"7": "Reject False Teachers",
 		},
 	},
 	"2 Peter": {
 		"1": {
-			"1":  "God's Power for Godly Lives",
-			"16": "Listen to God's Words",
+			"1":  "God’s Power for Godly Lives",
+			"16": "Listen to God’s Words",
 		},
 		"2": {
 			"1": "Warnings about False Teachers",
 		},
 		"3": {
-			"1":  "Be Ready for Christ's Return",
-			"8":  "God's Patience",
+			"1":  "Be Ready for Christ’s Return",
+			"8":  "God’s Patience",
 			"11": "Live Holy Lives",
 		},
 	},
 	"Deuteronomy": {
 		"1": {
 			"1":  "Moses Speaks to the People",
-			"19": "Moses Tells of Sending the Spies and the People's Rebellion",
+			"19": "Moses Tells of Sending the Spies and the People’s Rebellion",
 		},
 		"2": {
 			"1":  "The Story of the Wilderness Wanderings",
@@ -134,12 +134,12 @@ var verseTitlesExceptions = map[string]map[string]map[string]string{
 			"1": "Moses Reminds the People of Their Many Rebellions",
 		},
 		"10": {
-			"1": "God's Mercy in Restoring the Two Tablets of the Ten Commandments",
+			"1": "God’s Mercy in Restoring the Two Tablets of the Ten Commandments",
 		},
 		"11": {
 			"1":  "An Exhortation to Obey the Commandments",
-			"8":  "The Promise of God's Great Blessings",
-			"18": "A Careful Study of God's Words Is Required",
+			"8":  "The Promise of God’s Great Blessings",
+			"18": "A Careful Study of God’s Words Is Required",
 			"26": "A Blessing and a Curse Are Set before the People",
 		},
 		"12": {
@@ -148,11 +148,11 @@ var verseTitlesExceptions = map[string]map[string]map[string]string{
 		},
 		"13": {
 			"1":  "Dealing with False Prophets",
-			"6":  "Dealing with a Family Member's Idolatry",
+			"6":  "Dealing with a Family Member’s Idolatry",
 			"12": "Dealing with Idolatrous Cities",
 		},
 		"14": {
-			"1":  "God's Children Are Not to Disfigure Themselves in Mourning",
+			"1":  "God’s Children Are Not to Disfigure Themselves in Mourning",
 			"22": "Giving God One-Tenth of Everything",
 		},
 		"15": {
@@ -221,7 +221,7 @@ var verseTitlesExceptions = map[string]map[string]map[string]string{
 			"15": "Curses from the Lord",
 		},
 		"29": {
-			"1":  "Israel's Past, Present, and Future",
+			"1":  "Israel’s Past, Present, and Future",
 			"10": "All Are Presented before the Lord to Enter into His Covenant",
 		},
 		"30": {
@@ -231,11 +231,11 @@ var verseTitlesExceptions = map[string]map[string]map[string]string{
 		},
 		"31": {
 			"1":  "Joshua Will Lead the People",
-			"9":  "Moses Encourages Reading God's Law",
+			"9":  "Moses Encourages Reading God’s Law",
 			"14": "God Gives a Charge to Joshua",
 		},
 		"32": {
-			"1": "Moses' Song Which Sets Forth God's Mercy and Vengeance",
+			"1": "Moses’ Song Which Sets Forth God’s Mercy and Vengeance",
 		},
 		"33": {
 			"1":  "The Blessings of the Twelve Tribes",
@@ -255,10 +255,15 @@ var enhancedPath = "./json/enhanced"
 var cachePath = "./cache"
 
 func main() {
+	if len(os.Args) > 1 {
+		runCommand(os.Args[1], os.Args[2:])
+		return
+	}
 	loadInitialBooks()
 	fetchBibleData()
 	// applyEnhancements()
-	// writeEnhancedBooks()
+	// applyPsalmHeadings()
+	// writeEnhancedBooks(os.Stdout)
 }
 
 func getEnhancedBook(title string) *BookEnhanced {
@@ -270,35 +275,38 @@ func getEnhancedBook(title string) *BookEnhanced {
 	return nil
 }
 
-func loadInitialBooks() {
-	files, err := ioutil.ReadDir(initialPath)
-	if err != nil {
-		panic(err)
+func getEnhancedChapter(book, chapter string) *ChapterEnhanced {
+	eb := getEnhancedBook(book)
+	if eb == nil {
+		return nil
 	}
-	fmt.Println("reading initial data...")
-	for _, file := range files {
-		if filepath.Ext(file.Name()) != ".json" {
-			continue
+	nb, _ := strconv.Atoi(chapter)
+	for _, c := range eb.Chapters {
+		if c.Nb == nb {
+			return c
 		}
-		bytes, err := ioutil.ReadFile(initialPath + "/" + file.Name())
+	}
+	return nil
+}
+
+func loadInitialBooks() {
+	verifyBookNames()
+	fmt.Println("reading initial data...")
+	for _, info := range catalogue {
+		bytes, err := ioutil.ReadFile(initialPath + "/" + info.FileName() + ".json")
 		if err != nil {
 			log.Fatal(err)
 		}
 		book := new(Book)
 		if err = json.Unmarshal(bytes, book); err == nil {
+			if len(book.Chapters) != info.Chapters() {
+				logError(fmt.Errorf("%s has %d chapters, expecting %d", info.Name, len(book.Chapters), info.Chapters()))
+			}
 			enhancedBook := convertBookToEnhanced(book)
 			enhanced = append(enhanced, enhancedBook)
 			for _, c := range enhancedBook.Chapters {
-				c := c
 				for _, v := range c.Verses {
-					v := v
-					if _, ok := enhancedVerses[enhancedBook.Title]; !ok {
-						enhancedVerses[enhancedBook.Title] = make(map[int]map[int]*VerseEnhanced)
-					}
-					if _, ok := enhancedVerses[enhancedBook.Title][c.Nb]; !ok {
-						enhancedVerses[enhancedBook.Title][c.Nb] = make(map[int]*VerseEnhanced)
-					}
-					enhancedVerses[enhancedBook.Title][c.Nb][v.Nb] = v
+					enhancedVerses[v.ID] = v
 				}
 			}
 			initial = append(initial, book)
@@ -306,7 +314,38 @@ func loadInitialBooks() {
 	}
 }
 
+// loadEnhancedBooks reads the previously written enhanced books, filling in
+// verse ids for files written before they existed. The text is kept as stored,
+// the typography command being the one to normalize it.
+func loadEnhancedBooks() {
+	fmt.Println("reading enhanced data...")
+	enhanced = nil
+	enhancedVerses = make(map[VerseID]*VerseEnhanced)
+	resetWordIndexes()
+	for _, info := range catalogue {
+		bytes, err := ioutil.ReadFile(enhancedPath + "/" + info.FileName() + ".json")
+		if err != nil {
+			log.Fatal(err)
+		}
+		book := new(BookEnhanced)
+		if err := json.Unmarshal(bytes, book); err != nil {
+			log.Fatalf("failed to read enhanced book %s: %v", info.Name, err)
+		}
+		for _, c := range book.Chapters {
+			for _, v := range c.Verses {
+				if v.ID == 0 {
+					v.ID = newVerseID(info.Nb, c.Nb, v.Nb)
+					v.OSIS = v.ID.OSIS()
+				}
+				enhancedVerses[v.ID] = v
+			}
+		}
+		enhanced = append(enhanced, book)
+	}
+}
+
 func convertBookToEnhanced(book *Book) *BookEnhanced {
+	info := mustBookInfo(book.Book)
 	var chaptersEn []*ChapterEnhanced
 	for _, chap := range book.Chapters {
 		chapEn := new(ChapterEnhanced)
@@ -314,6 +353,7 @@ func convertBookToEnhanced(book *Book) *BookEnhanced {
 		if err != nil {
 			panic(fmt.Errorf("faield to convert chapter.chapter to int: %s", chap.Chapter))
 		}
+		chapEn.Nb = nb
 		var versesEn []*VerseEnhanced
 		for _, verse := range chap.Verses {
 			verseEn := new(VerseEnhanced)
@@ -322,6 +362,8 @@ func convertBookToEnhanced(book *Book) *BookEnhanced {
 				panic(fmt.Errorf("failed to convert verse to int: %s", verse.Verse))
 			}
 			verseEn.Nb = nb
+			verseEn.ID = newVerseID(info.Nb, chapEn.Nb, nb)
+			verseEn.OSIS = verseEn.ID.OSIS()
 			verseEn.Text = verse.Text
 			if verse.Title != "" {
 				verseEn.Title = verse.Title
@@ -330,7 +372,6 @@ func convertBookToEnhanced(book *Book) *BookEnhanced {
 			versesEn = append(versesEn, verseEn)
 		}
 		chapEn.Verses = versesEn
-		chapEn.Nb = nb
 		chaptersEn = append(chaptersEn, chapEn)
 	}
 	return &BookEnhanced{
@@ -397,11 +438,9 @@ func scrapeURL(url string) (res string, err error) {
 }
 
 func getFullUrl(book *Book, chapter *Chapter) string {
-	bookPath := strings.ReplaceAll(strings.ToLower(book.Book+"/"), " ", "-")
-	chapterPath := strings.ToLower(fmt.Sprintf("%s-chapter-%s", strings.ReplaceAll(book.Book, " ", "-"), chapter.Chapter))
-	if book.Book == "1-samuel" && chapter.Chapter == "16" {
-		fmt.Println("salut: ")
-	}
+	info := mustBookInfo(book.Book)
+	bookPath := info.Slug() + "/"
+	chapterPath := fmt.Sprintf("%s-chapter-%s", info.Slug(), chapter.Chapter)
 	// some URL don't have the one that they should so replace with exception
 	if val, ok := urlExceptions[chapterPath]; ok {
 		chapterPath = val
@@ -416,15 +455,15 @@ func logError(err error) {
 	if _, err := os.Stat(logFile); os.IsNotExist(err) {
 		_ = ioutil.WriteFile(logFile, []byte(""), 0644)
 	}
-	file, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	file, openErr := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if file != nil {
 		defer file.Close()
 	}
-	if err != nil {
-		log.Println("error opening log file:", err)
+	if openErr != nil {
+		log.Println("error opening log file:", openErr)
 		return
 	}
-	if _, err := file.WriteString(err.Error()); err != nil {
+	if _, err := file.WriteString(err.Error() + "\n"); err != nil {
 		log.Println("error writing to log file:", err)
 	}
 }
@@ -445,6 +484,9 @@ func tryWriteEnhancements(book *Book, chapter *Chapter, htmlData string) {
 		fmt.Println("failed to create document")
 		panic(err)
 	}
+	// red letters, italics and layout are matched against the verse texts once they are set
+	defer captureSpans(book, chapter, document)
+	defer captureLayout(book, chapter, document)
 
 	exception := getException(book, chapter)
 	if err := titleIsExpectedD(document, book, chapter); exception == nil && err != nil {
@@ -473,7 +515,7 @@ func tryWriteEnhancements(book *Book, chapter *Chapter, htmlData string) {
 		return
 	}
 
-	if book.Book == "Psalms" {
+	if mustBookInfo(book.Book).OSIS == "Ps" {
 		parsePsalmsChapter(book, chapter, document)
 		return
 	}
@@ -515,7 +557,7 @@ func tryWriteEnhancements(book *Book, chapter *Chapter, htmlData string) {
 
 		// text
 		text = par.Text()
-		text = strings.ReplaceAll(text, "\u2009", " ") // replace thin spaces by spaces
+		text = normalizeSpaces(text) // replace thin and non-breaking spaces by spaces
 		text = strings.TrimSpace(text)
 		currT = ""
 		if text == title {
@@ -595,19 +637,31 @@ func parsePsalmsChapter(book *Book, chapter *Chapter, doc *goquery.Document) {
 	verseTitle := ""
 	fmt.Println("what is up")
 	text := doc.Find(".post").Text()
-	text = strings.ReplaceAll(text, "\u2009", " ") // replace thin spaces by spaces
+	text = normalizeSpaces(text) // replace thin and non-breaking spaces by spaces
 	startInd := strings.Index(text, titles[0])
 
 	// title at top
 	if startInd != -1 {
 		verseTitle = strings.TrimSpace(titles[0])
-		createEnhancement(book, chapter, 1, verseTitle)
+		if isSuperscription(verseTitle) {
+			setSuperscription(book, chapter, verseTitle)
+		} else if acrosticVerse(book, chapter, verseTitle) == 0 {
+			createEnhancement(book, chapter, 1, verseTitle)
+		}
 	}
 	text = text[startInd+len(titles[0]):]
 	if i := strings.Index(text, "< Previous Chapter"); i != -1 {
 		text = text[:i-1]
 		text = strings.TrimSpace(text)
 	}
+	// superscription written as plain text before the first verse
+	if i := strings.Index(text, verseStartText(1)); i > 0 {
+		if pre := strings.TrimSpace(text[:i]); pre != "" && acrosticVerse(book, chapter, pre) == 0 {
+			setSuperscription(book, chapter, pre)
+		}
+		text = text[i:]
+	}
+	defer verifySuperscription(book, chapter)
 
 	curVerse := 0
 	for text != "" {
@@ -615,7 +669,10 @@ func parsePsalmsChapter(book *Book, chapter *Chapter, doc *goquery.Document) {
 		curVerse++
 		if verseTitle = endsWithTitle(text, titles, curVerse); len(verseTitle) > 0 {
 			verseTitle = strings.TrimSpace(verseTitle)
-			createEnhancement(book, chapter, curVerse+1, verseTitle)
+			// Psalm 119 stanza letters are set by applyPsalmHeadings
+			if acrosticVerse(book, chapter, verseTitle) == 0 {
+				createEnhancement(book, chapter, curVerse+1, verseTitle)
+			}
 			ind := strings.Index(text, verseTitle)
 			// remove title from text
 			text = text[:ind] + text[ind+len(verseTitle):]
@@ -660,7 +717,7 @@ func parseSingleChapter(book *Book, chapter *Chapter, doc *goquery.Document) {
 	verseTitle := ""
 	fmt.Println("what is up")
 	text := doc.Find(".post").Text()
-	text = strings.ReplaceAll(text, "\u2009", " ") // replace thin spaces by spaces
+	text = normalizeSpaces(text) // replace thin and non-breaking spaces by spaces
 	startInd := strings.Index(text, titles[0])
 
 	// title at top
@@ -764,24 +821,13 @@ func verseStartText(verse int) string {
 }
 
 func setEnhancedVerseText(book, chapter, verse, text string) {
-	var v *VerseEnhanced
-	eb := getEnhancedBook(book)
 	cNb, _ := strconv.Atoi(chapter)
 	vNb, _ := strconv.Atoi(verse)
-	for _, c := range eb.Chapters {
-		if c.Nb == cNb {
-			for _, ver := range c.Verses {
-				if ver.Nb == vNb {
-					v = ver
-					break
-				}
-			}
-		}
-	}
+	v := enhancedVerses[newVerseID(mustBookInfo(book).Nb, cNb, vNb)]
 	if v == nil {
 		panic(fmt.Errorf("failed to find en: %s", book+"-"+chapter+"-"+verse))
 	}
-	v.Text = text
+	v.Text = normalizeText(text)
 	fmt.Printf("set %s - %s - %s with text: %s\n", book, chapter, verse, text)
 }
 
@@ -830,7 +876,7 @@ func titleIsExpectedD(document *goquery.Document, book *Book, chapter *Chapter)
 }
 
 func isGenesis1(book *Book, chapter *Chapter) bool {
-	return book.Book == "Genesis" && chapter.Chapter == "1"
+	return mustBookInfo(book.Book).Nb == 1 && chapter.Chapter == "1"
 }
 
 func cacheData(book *Book, chapter *Chapter, bytes []byte) {
@@ -846,19 +892,21 @@ func cacheData(book *Book, chapter *Chapter, bytes []byte) {
 
 func getCacheFileName(book *Book, chapter *Chapter) string {
 	wd, _ := os.Getwd()
-	return path.Join(wd, cachePath, strings.ReplaceAll(strings.ToLower(fmt.Sprintf("%s-%s.html", book.Book, chapter.Chapter)), " ", ""))
+	return path.Join(wd, cachePath, strings.ToLower(fmt.Sprintf("%s-%s.html", mustBookInfo(book.Book).FileName(), chapter.Chapter)))
 }
 
 func applyEnhancements() {
 	for _, en := range enhancements {
-		verse := enhancedVerses[en.book][en.chapter][en.verse]
-		verse.Title = en.title
+		verse := enhancedVerses[newVerseID(mustBookInfo(en.book).Nb, en.chapter, en.verse)]
+		verse.Title = normalizeText(en.title)
 		fmt.Printf("set new verse: %s - %d - %d: %s\n", en.book, en.chapter, en.verse, verse.Title)
 	}
 	fmt.Printf("applied %v enhancements!\n", len(enhancements))
 }
 
-func writeEnhancedBooks() {
+// writeEnhancedBooks rewrites the enhanced books and their stats, listing the
+// files written to out.
+func writeEnhancedBooks(out io.Writer) {
 	os.RemoveAll(enhancedPath)
 	if err := os.MkdirAll(enhancedPath, 0777); err != nil {
 		panic(err)
@@ -871,12 +919,13 @@ func writeEnhancedBooks() {
 			panic(err)
 		}
 		wroteCount++
-		fileName := fmt.Sprintf("%s/%s.json", enhancedPath, strings.ReplaceAll(book.Title, " ", ""))
+		fileName := fmt.Sprintf("%s/%s.json", enhancedPath, mustBookInfo(book.Title).FileName())
 		if err := ioutil.WriteFile(fileName, bytes, 0777); err == nil {
-			fmt.Println("wrote file: ", fileName)
+			fmt.Fprintln(out, "wrote file: ", fileName)
 		}
 	}
-	fmt.Printf("wrote a total of %v books!\n", wroteCount)
+	fmt.Fprintf(out, "wrote a total of %v books!\n", wroteCount)
+	writeEnhancedStats(enhancedPath, out)
 
 }
 
@@ -917,18 +966,30 @@ type Verse struct {
 }
 
 type BookEnhanced struct {
-	Title    string             `json:"title,omitempty"`
-	Chapters []*ChapterEnhanced `json:"chapters"`
+	Title        string             `json:"title,omitempty"`
+	Introduction string             `json:"introduction,omitempty"`
+	Chapters     []*ChapterEnhanced `json:"chapters"`
 }
 
 type ChapterEnhanced struct {
-	Nb     int              `json:"nb"`
-	Verses []*VerseEnhanced `json:"verses"`
+	Nb             int              `json:"nb"`
+	Superscription string           `json:"superscription,omitempty"`
+	Summary        string           `json:"summary,omitempty"`
+	Verses         []*VerseEnhanced `json:"verses"`
 }
 
 type VerseEnhanced struct {
-	Subtitle string `json:"subtitle,omitempty"`
-	Title    string `json:"title,omitempty"`
-	Nb       int    `json:"nb"`
-	Text     string `json:"text"`
+	ID            VerseID    `json:"id"`
+	OSIS          string     `json:"osis"`
+	Subtitle      string     `json:"subtitle,omitempty"`
+	Title         string     `json:"title,omitempty"`
+	Nb            int        `json:"nb"`
+	Text          string     `json:"text"`
+	Paragraph     bool       `json:"paragraph,omitempty"`
+	Stanza        bool       `json:"stanza,omitempty"`
+	StanzaHeading string     `json:"stanzaHeading,omitempty"`
+	Lines         []int      `json:"lines,omitempty"`
+	Spans         []Span     `json:"spans,omitempty"`
+	Tokens        []Token    `json:"tokens,omitempty"`
+	CrossRefs     []CrossRef `json:"crossRefs,omitempty"`
 }
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// archaicWords maps the archaic KJV forms to their modern equivalent, the
+// verb endings being handled by modernWord.
+var archaicWords = map[string]string{
+	"thee": "you", "thou": "you", "ye": "you",
+	"thy": "your", "thine": "your", "thyself": "yourself",
+	"art": "are", "wast": "were", "wert": "were",
+	"hath": "has", "hast": "have", "hadst": "had",
+	"doth": "does", "doeth": "does", "dost": "do", "doest": "do", "didst": "did",
+	"saith": "says", "sayest": "say", "spake": "spoke", "sware": "swore",
+	"shalt": "shall", "wilt": "will", "canst": "can",
+	"couldest": "could", "wouldest": "would", "shouldest": "should", "mayest": "may", "mightest": "might",
+	"unto": "to", "yea": "yes", "nay": "no",
+}
+
+// ethNouns end in -eth without being verbs, the names whose stem isn't a word
+// being left alone by verbBase.
+var ethNouns = makeSet(strings.Fields(`beth heth seth sheth teeth japheth zereth shibboleth twentieth thirtieth fortieth
+	fiftieth sixtieth seventieth eightieth ninetieth`))
+
+// modernVocabulary is the set of words of the enhanced verses, used to find
+// the base of a verb, "loveth" being "love" and not "lov".
+var modernVocabulary map[string]bool
+
+func isVocabularyWord(word string) bool {
+	if modernVocabulary == nil {
+		modernVocabulary = make(map[string]bool)
+		for _, v := range enhancedVerses {
+			for _, w := range textWords(v.Text) {
+				modernVocabulary[w] = true
+			}
+		}
+	}
+	return modernVocabulary[word]
+}
+
+// verbBase rebuilds the base of a verb whose archaic ending was removed, ok
+// is false when the base isn't a word of the text, like the stem of a name.
+func verbBase(stem string) (base string, ok bool) {
+	n := len(stem)
+	switch {
+	case isVocabularyWord(stem + "e"):
+		return stem + "e", true
+	case isVocabularyWord(stem):
+		return stem, true
+	case n > 2 && stem[n-1] == stem[n-2] && isVocabularyWord(stem[:n-1]):
+		return stem[:n-1], true
+	case strings.HasSuffix(stem, "i") && isVocabularyWord(stem[:n-1]+"y"):
+		return stem[:n-1] + "y", true
+	case isVocabularyWord(stem+"ed") || isVocabularyWord(stem+"ing"):
+		// "challeng" and "trickl" lost their e
+		if strings.HasSuffix(stem, "v") || strings.HasSuffix(stem, "g") && !strings.HasSuffix(stem, "gg") ||
+			n > 1 && stem[n-1] == 'l' && !strings.ContainsRune("aeioul", rune(stem[n-2])) {
+			return stem + "e", true
+		}
+		if n > 2 && stem[n-1] == stem[n-2] && !strings.ContainsRune("lsf", rune(stem[n-1])) {
+			return stem[:n-1], true
+		}
+		return stem, true
+	}
+	return stem, false
+}
+
+// thirdPerson adds the modern -s ending, "go" giving "goes" and "cry" "cries".
+func thirdPerson(base string) string {
+	switch {
+	case strings.HasSuffix(base, "y") && len(base) > 1 && !strings.ContainsAny(base[len(base)-2:len(base)-1], "aeiou"):
+		return base[:len(base)-1] + "ies"
+	case strings.HasSuffix(base, "s"), strings.HasSuffix(base, "x"), strings.HasSuffix(base, "z"),
+		strings.HasSuffix(base, "ch"), strings.HasSuffix(base, "sh"), strings.HasSuffix(base, "o"):
+		return base + "es"
+	}
+	return base + "s"
+}
+
+// modernWord gives the modern form of a lower cased word, thou tells whether
+// the word goes with "thou" so its -est ending is a verb one, "thou knowest"
+// giving "know" while "greatest" is kept.
+func modernWord(word string, thou bool) string {
+	if modern, ok := archaicWords[word]; ok {
+		return modern
+	}
+	switch {
+	case len(word) > 4 && strings.HasSuffix(word, "eth") && !ethNouns[word]:
+		if base, ok := verbBase(strings.TrimSuffix(word, "eth")); ok {
+			return thirdPerson(base)
+		}
+	case thou && len(word) > 4 && strings.HasSuffix(word, "edst"):
+		return strings.TrimSuffix(word, "st")
+	case thou && len(word) > 4 && strings.HasSuffix(word, "est"):
+		if base, ok := verbBase(strings.TrimSuffix(word, "est")); ok {
+			return base
+		}
+	}
+	return word
+}
+
+// modernizeWords maps the archaic forms of a run of words, e.g. the result of
+// textWords, the words near a "thou" getting the -est endings removed.
+func modernizeWords(words []string) []string {
+	modern := make([]string, len(words))
+	for i, w := range words {
+		thou := (i > 0 && words[i-1] == "thou") || (i > 1 && words[i-2] == "thou") || (i+1 < len(words) && words[i+1] == "thou")
+		modern[i] = modernWord(w, thou)
+	}
+	return modern
+}
+
+// searchWords splits a text in the words matched by a search, modernized or not.
+func searchWords(text string, modern bool) []string {
+	words := textWords(text)
+	if modern {
+		words = modernizeWords(words)
+	}
+	return words
+}
+
+// resetWordIndexes drops the vocabulary and the search indexes built from the
+// verses, for when the books are loaded again or their text changes.
+func resetWordIndexes() {
+	modernVocabulary = nil
+	searchIndexes = make(map[bool]map[string][]VerseID)
+}
+
+// searchIndexes are the inverted indexes of the verses, by word, built on
+// the first search with or without the modern forms.
+var searchIndexes = make(map[bool]map[string][]VerseID)
+
+func searchIndex(modern bool) map[string][]VerseID {
+	if index, ok := searchIndexes[modern]; ok {
+		return index
+	}
+	index := make(map[string][]VerseID)
+	for _, book := range enhanced {
+		for _, c := range book.Chapters {
+			for _, v := range c.Verses {
+				seen := make(map[string]bool)
+				for _, w := range searchWords(v.Text, modern) {
+					if !seen[w] {
+						seen[w] = true
+						index[w] = append(index[w], v.ID)
+					}
+				}
+			}
+		}
+	}
+	searchIndexes[modern] = index
+	return index
+}
+
+// SearchVerses returns the verses holding every word of the query, when
+// modern is set "you" also finds "thee", "thou" and "ye" and "has" finds "hath",
+// the verse text is left untouched.
+func SearchVerses(query string, modern bool) []VerseID {
+	index := searchIndex(modern)
+	var found []VerseID
+	for i, w := range searchWords(query, modern) {
+		ids := index[w]
+		if i == 0 {
+			found = append([]VerseID(nil), ids...)
+			continue
+		}
+		in := make(map[VerseID]bool)
+		for _, id := range ids {
+			in[id] = true
+		}
+		var kept []VerseID
+		for _, id := range found {
+			if in[id] {
+				kept = append(kept, id)
+			}
+		}
+		found = kept
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i] < found[j] })
+	return found
+}
+
+func searchCommand(args []string) {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	modern := fs.Bool("modern", true, "match the archaic forms with the modern ones, thee with you")
+	limit := fs.Int("limit", 0, "maximum number of verses printed, 0 for all")
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: search [-modern=false] [-limit 20] <words>")
+		os.Exit(2)
+	}
+
+	loadEnhancedDataQuiet()
+	found := SearchVerses(strings.Join(fs.Args(), " "), *modern)
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	for i, id := range found {
+		if *limit > 0 && i == *limit {
+			break
+		}
+		fmt.Fprintf(out, "%s  %s\n", id, enhancedVerses[id].Text)
+	}
+	fmt.Fprintf(out, "%v verses\n", len(found))
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func searchBooks() []*BookEnhanced {
+	return []*BookEnhanced{{Title: "Psalms", Chapters: []*ChapterEnhanced{{Nb: 23, Verses: []*VerseEnhanced{
+		roundTripVerse("Psalms", 23, 1, "", "The LORD is my shepherd; I shall not want."),
+		roundTripVerse("Psalms", 23, 3, "", "He restoreth my soul: he leadeth me in the paths of righteousness for his name’s sake."),
+		roundTripVerse("Psalms", 23, 4, "", "Yea, though I walk through the valley of the shadow of death, I will fear no evil: for thou art with me; thy rod and thy staff they comfort me."),
+	}}}}}
+}
+
+func TestSearchVerses(t *testing.T) {
+	useBooks(t, searchBooks())
+	tests := []struct {
+		query  string
+		modern bool
+		want   []VerseID
+	}{
+		{"name's", false, []VerseID{newVerseID(19, 23, 3)}},
+		{"name’s", false, []VerseID{newVerseID(19, 23, 3)}},
+		{"the LORD", false, []VerseID{newVerseID(19, 23, 1)}},
+		{"you are", true, []VerseID{newVerseID(19, 23, 4)}},
+		{"you are", false, nil},
+		{"shepherd staff", false, nil},
+	}
+	for _, test := range tests {
+		if got := SearchVerses(test.query, test.modern); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("SearchVerses(%q, %v) = %v, expecting %v", test.query, test.modern, got, test.want)
+		}
+	}
+}
+
+func TestSearchReloadedBooks(t *testing.T) {
+	useBooks(t, searchBooks())
+	if found := SearchVerses("shepherd", false); len(found) != 1 {
+		t.Fatalf("found %v, expecting Psalms 23:1", found)
+	}
+	if got := modernWord("giveth", false); got != "giveth" {
+		t.Fatalf("modernWord(giveth) = %q without give in the verses", got)
+	}
+	useBooks(t, []*BookEnhanced{{Title: "John", Chapters: []*ChapterEnhanced{{Nb: 10, Verses: []*VerseEnhanced{
+		roundTripVerse("John", 10, 11, "", "I am the good shepherd: the good shepherd giveth his life for the sheep."),
+		roundTripVerse("John", 10, 28, "", "And I give unto them eternal life; and they shall never perish."),
+	}}}}})
+	want := []VerseID{newVerseID(43, 10, 11)}
+	if found := SearchVerses("shepherd", false); !reflect.DeepEqual(found, want) {
+		t.Errorf("found %v after loading other books, expecting %v", found, want)
+	}
+	if got := modernWord("giveth", false); got != "gives" {
+		t.Errorf("modernWord(giveth) = %q, the vocabulary wasn't rebuilt from the loaded books", got)
+	}
+}
+
+func TestModernWord(t *testing.T) {
+	tests := []struct {
+		word string
+		thou bool
+		want string
+	}{
+		{"thee", false, "you"},
+		{"hath", false, "has"},
+		{"leadeth", false, "leads"},
+		{"restoreth", false, "restores"},
+		{"japheth", false, "japheth"},
+		{"greatest", false, "greatest"},
+		{"walkedst", true, "walked"},
+	}
+	useBooks(t, []*BookEnhanced{{Title: "Psalms", Chapters: []*ChapterEnhanced{{Nb: 23, Verses: []*VerseEnhanced{
+		roundTripVerse("Psalms", 23, 2, "", "He maketh me to lie down in green pastures: he will lead me, and restore me, and make me greatest."),
+	}}}}})
+	for _, test := range tests {
+		if got := modernWord(test.word, test.thou); got != test.want {
+			t.Errorf("modernWord(%q, %v) = %q, expecting %q", test.word, test.thou, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// psalmsWithoutSuperscription are the psalms that have no title in the KJV,
+// every other psalm is expected to get one from the page.
+var psalmsWithoutSuperscription = map[int]bool{
+	1: true, 2: true, 10: true, 33: true, 43: true, 71: true, 91: true, 93: true,
+	94: true, 95: true, 96: true, 97: true, 99: true, 104: true, 105: true, 106: true,
+	107: true, 111: true, 112: true, 113: true, 114: true, 115: true, 116: true, 117: true,
+	118: true, 119: true, 135: true, 136: true, 137: true, 146: true, 147: true, 148: true,
+	149: true, 150: true,
+}
+
+// psalm119Letters are the headings of the 22 stanzas of eight verses of Psalm 119.
+var psalm119Letters = []string{
+	"ALEPH", "BETH", "GIMEL", "DALETH", "HE", "VAU", "ZAIN", "CHETH", "TETH", "JOD", "CAPH",
+	"LAMED", "MEM", "NUN", "SAMECH", "AIN", "PE", "TZADDI", "KOPH", "RESH", "SCHIN", "TAU",
+}
+
+var superscriptionsPath = "./json/superscriptions.tsv"
+
+// superscriptions are the psalm titles of the list by psalm number.
+var superscriptions = make(map[int]string)
+
+var superscriptionRegex = regexp.MustCompile(`(?i)^(A Psalm|A Song|A Prayer|David.s Psalm|To the chief Musician|Maschil|Michtam|Shiggaion)`)
+
+// isSuperscription tells the canonical psalm titles apart from the editorial headings of the page.
+func isSuperscription(text string) bool {
+	return superscriptionRegex.MatchString(strings.TrimSpace(text))
+}
+
+// acrosticVerse returns the verse of Psalm 119 starting the stanza headed by
+// the text, a letter name alone or next to its Hebrew letter, or 0.
+func acrosticVerse(book *Book, chapter *Chapter, text string) int {
+	if mustBookInfo(book.Book).OSIS != "Ps" || chapter.Chapter != "119" {
+		return 0
+	}
+	words := strings.Fields(text)
+	if len(words) == 2 && isHebrewLetter(words[0]) {
+		words = words[1:]
+	} else if len(words) == 2 && isHebrewLetter(words[1]) {
+		words = words[:1]
+	}
+	if len(words) != 1 {
+		return 0
+	}
+	name := strings.TrimRight(words[0], ".")
+	for i, letter := range psalm119Letters {
+		if strings.EqualFold(name, letter) {
+			return i*8 + 1
+		}
+	}
+	return 0
+}
+
+func isHebrewLetter(word string) bool {
+	word = strings.TrimRight(word, ".")
+	r, size := utf8.DecodeRuneInString(word)
+	return size == len(word) && unicode.Is(unicode.Hebrew, r)
+}
+
+func setSuperscription(book *Book, chapter *Chapter, text string) {
+	chap := getEnhancedChapter(book.Book, chapter.Chapter)
+	if chap == nil {
+		panic(fmt.Errorf("failed to find chapter: %s - %s", book.Book, chapter.Chapter))
+	}
+	chap.Superscription = strings.TrimSpace(text)
+	fmt.Printf("SUPERSCRIPTION: %s - %s: %s\n", book.Book, chapter.Chapter, chap.Superscription)
+}
+
+// verifySuperscription logs the psalms whose superscription went missing or
+// that got one they shouldn't have.
+func verifySuperscription(book *Book, chapter *Chapter) {
+	if chap := getEnhancedChapter(book.Book, chapter.Chapter); chap != nil {
+		checkSuperscription(chap)
+	}
+}
+
+func checkSuperscription(chap *ChapterEnhanced) {
+	expected := !psalmsWithoutSuperscription[chap.Nb]
+	if expected && chap.Superscription == "" {
+		logError(fmt.Errorf("missing superscription for Psalm %d", chap.Nb))
+	}
+	if !expected && chap.Superscription != "" {
+		logError(fmt.Errorf("unexpected superscription for Psalm %d: %s", chap.Nb, chap.Superscription))
+	}
+}
+
+// applyPsalmHeadings sets the acrostic stanza headings of Psalm 119, which
+// don't depend on the page.
+func applyPsalmHeadings() {
+	chap := getEnhancedChapter("Psalms", "119")
+	if chap == nil {
+		return
+	}
+	for i, letter := range psalm119Letters {
+		for _, v := range chap.Verses {
+			if v.Nb == i*8+1 {
+				v.StanzaHeading = letter
+				v.Stanza = true
+			}
+		}
+	}
+}
+
+// loadSuperscriptions reads the psalm titles, one psalm per line with its
+// title after a tab: "Psalms 3	A Psalm of David, when he fled from Absalom his son.".
+func loadSuperscriptions(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("failed to open superscriptions %s: %w", file, err)
+	}
+	defer f.Close()
+
+	fmt.Println("reading superscriptions...")
+	superscriptions = make(map[int]string)
+	scanner := bufio.NewScanner(f)
+	lineNb := 0
+	for scanner.Scan() {
+		lineNb++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		cols := strings.SplitN(line, "\t", 2)
+		r, err := parseReference(cols[0])
+		if err != nil {
+			logError(fmt.Errorf("%s:%d: %w", file, lineNb, err))
+			continue
+		}
+		info, chapter := r.Start.BookInfo(), r.Start.Chapter()
+		whole := r.Start.Verse() == 1 && r.End == newVerseID(info.Nb, chapter, info.Verses[chapter-1])
+		if info.OSIS != "Ps" || !whole || len(cols) != 2 {
+			logError(fmt.Errorf("%s:%d: expecting a psalm and its title: %s", file, lineNb, line))
+			continue
+		}
+		superscriptions[chapter] = strings.TrimSpace(cols[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read superscriptions %s: %w", file, err)
+	}
+	fmt.Printf("read %v superscriptions\n", len(superscriptions))
+	return nil
+}
+
+// applySuperscriptions sets the titles of the list on the psalms that didn't
+// get one from the scraped pages, then logs the psalms still off.
+func applySuperscriptions() {
+	book := getEnhancedBook(bookByNb(19).Name)
+	if book == nil {
+		return
+	}
+	for _, chap := range book.Chapters {
+		if text, ok := superscriptions[chap.Nb]; ok && chap.Superscription == "" {
+			chap.Superscription = text
+		}
+		checkSuperscription(chap)
+	}
+}
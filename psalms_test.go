@@ -0,0 +1,60 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestAcrosticVerse(t *testing.T) {
+	tests := []struct {
+		book, chapter, text string
+		verse               int
+	}{
+		{"Psalms", "119", "ALEPH.", 1},
+		{"Psalm", "119", "ב BETH", 9},
+		{"Ps", "119", "TAU ת", 169},
+		{"Psalms", "119", "Blessed are the undefiled", 0},
+		{"Psalms", "118", "ALEPH", 0},
+		{"Lamentations", "119", "ALEPH", 0},
+	}
+	for _, test := range tests {
+		book, chapter := &Book{Book: test.book}, &Chapter{Chapter: test.chapter}
+		if got := acrosticVerse(book, chapter, test.text); got != test.verse {
+			t.Errorf("acrosticVerse(%s %s, %q) = %d, expecting %d", test.book, test.chapter, test.text, got, test.verse)
+		}
+	}
+}
+
+func TestLoadSuperscriptions(t *testing.T) {
+	saved := superscriptions
+	defer func() { superscriptions = saved }()
+	file := filepath.Join(t.TempDir(), "superscriptions.tsv")
+	data := "# psalm titles\nPsalms 3\tA Psalm of David, when he fled from Absalom his son.\nPs 145\tDavid’s Psalm of praise.\n"
+	if err := ioutil.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadSuperscriptions(file); err != nil {
+		t.Fatal(err)
+	}
+	if len(superscriptions) != 2 {
+		t.Errorf("%d superscriptions read, expecting 2", len(superscriptions))
+	}
+	if got := superscriptions[145]; got != "David’s Psalm of praise." {
+		t.Errorf("Psalm 145 title is %q", got)
+	}
+}
+
+func TestShippedSuperscriptions(t *testing.T) {
+	saved := superscriptions
+	defer func() { superscriptions = saved }()
+	if err := loadSuperscriptions(superscriptionsPath); err != nil {
+		t.Fatal(err)
+	}
+	for nb := 1; nb <= 150; nb++ {
+		_, ok := superscriptions[nb]
+		if ok == psalmsWithoutSuperscription[nb] {
+			t.Errorf("Psalm %d has a title: %v, expecting %v", nb, ok, !ok)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type readOptions struct {
+	width    int
+	margin   bool
+	markdown bool
+}
+
+const marginWidth = 5
+
+// renderRange lays out the verses of the range as plain text or markdown,
+// with the section titles as headings and a heading for every chapter.
+func renderRange(r VerseRange, opts readOptions) string {
+	var b strings.Builder
+	var par []string
+	flushPar := func() {
+		if len(par) == 0 {
+			return
+		}
+		text := strings.Join(par, " ")
+		if opts.markdown {
+			b.WriteString(text + "\n\n")
+		} else {
+			b.WriteString(strings.Join(wrapText(text, opts.width, "", ""), "\n") + "\n\n")
+		}
+		par = nil
+	}
+	heading := func(level int, text string) {
+		flushPar()
+		if b.Len() > 0 && !strings.HasSuffix(b.String(), "\n\n") {
+			b.WriteString("\n")
+		}
+		if opts.markdown {
+			b.WriteString(strings.Repeat("#", level) + " " + text + "\n\n")
+		} else if level <= 2 {
+			b.WriteString(strings.ToUpper(text) + "\n\n")
+		} else {
+			b.WriteString(text + "\n\n")
+		}
+	}
+
+	lastChapter := 0
+	for _, id := range r.IDs() {
+		v := enhancedVerses[id]
+		if v == nil {
+			logError(fmt.Errorf("verse not loaded: %s", id))
+			continue
+		}
+		if c := id.Book()*1000 + id.Chapter(); c != lastChapter {
+			lastChapter = c
+			chapter := id.Chapter()
+			heading(2, fmt.Sprintf("%s %d", id.BookInfo().DisplayName(), chapter))
+			chap := getEnhancedChapter(id.BookInfo().Name, strconv.Itoa(chapter))
+			if chap.Summary != "" && opts.markdown {
+				b.WriteString("> " + strings.Join(strings.Fields(chap.Summary), " ") + "\n\n")
+			}
+			if chap.Superscription != "" && id.Verse() == 1 {
+				if opts.markdown {
+					b.WriteString("*" + chap.Superscription + "*\n\n")
+				} else {
+					b.WriteString(strings.Join(wrapText(chap.Superscription, opts.width, "", ""), "\n") + "\n\n")
+				}
+			}
+		}
+		if v.Title != "" {
+			heading(3, v.Title)
+		}
+		if v.StanzaHeading != "" {
+			heading(4, v.StanzaHeading)
+		}
+		if v.Paragraph || v.Stanza {
+			flushPar()
+		}
+
+		lines := []string{readVerseText(v.Text, v.Spans, opts)}
+		if len(v.Lines) > 0 {
+			lines = nil
+			for _, line := range verseLines(v) {
+				lines = append(lines, readVerseText(line.Text, line.Spans, opts))
+			}
+		}
+		number := strconv.Itoa(v.Nb)
+		if opts.markdown {
+			number = "**" + number + "**"
+		}
+
+		switch {
+		case opts.margin && !opts.markdown:
+			flushPar()
+			prefix := fmt.Sprintf("%*s ", marginWidth-1, number)
+			indent := strings.Repeat(" ", marginWidth)
+			for i, line := range lines {
+				if i > 0 {
+					prefix = indent + "  "
+				}
+				b.WriteString(strings.Join(wrapText(line, opts.width, prefix, indent), "\n") + "\n")
+			}
+		case len(lines) > 1:
+			// poetry keeps its lines
+			flushPar()
+			for i, line := range lines {
+				if i == 0 {
+					line = number + " " + line
+				}
+				if opts.markdown {
+					b.WriteString(line + "  \n")
+				} else {
+					b.WriteString(strings.Join(wrapText(line, opts.width, "", "  "), "\n") + "\n")
+				}
+			}
+			b.WriteString("\n")
+		default:
+			par = append(par, number+" "+lines[0])
+		}
+	}
+	flushPar()
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+func readVerseText(text string, spans []Span, opts readOptions) string {
+	if !opts.markdown {
+		return text
+	}
+	return renderSpans(text, spans,
+		func(style string, depth int) string { return markdownSpanMarks[style] },
+		func(style string, depth int) string { return markdownSpanMarks[style] },
+		func(s string) string { return s })
+}
+
+var markdownSpanMarks = map[string]string{
+	SpanSupplied: "*",
+}
+
+// wrapText breaks text into lines of at most width characters, the first
+// line starting with prefix and the next ones with indent.
+func wrapText(text string, width int, prefix, indent string) []string {
+	var lines []string
+	line := prefix
+	empty := true
+	for _, word := range strings.Fields(text) {
+		if !empty && width > 0 && utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) > width {
+			lines = append(lines, line)
+			line = indent
+			empty = true
+		}
+		if !empty {
+			line += " "
+		}
+		line += word
+		empty = false
+	}
+	return append(lines, line)
+}
+
+func readCommand(args []string) {
+	fs := flag.NewFlagSet("read", flag.ExitOnError)
+	opts := readOptions{}
+	fs.IntVar(&opts.width, "width", 0, "wrap width, defaults to the terminal width")
+	fs.BoolVar(&opts.margin, "margin", false, "print verse numbers in the margin instead of inline")
+	fs.BoolVar(&opts.markdown, "markdown", false, "print markdown")
+	scheme := fs.String("versification", kjvScheme, "versification of the reference, e.g. hebrew")
+	lang := localeFlag(fs)
+	_ = fs.Parse(args)
+	useLocale(*lang)
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: read [-width n] [-margin] [-markdown] [-versification hebrew] [-lang fr] <reference>")
+		os.Exit(2)
+	}
+	if opts.width == 0 {
+		opts.width = terminalWidth()
+	}
+	if opts.width == 0 {
+		opts.width, _ = strconv.Atoi(os.Getenv("COLUMNS"))
+	}
+	if opts.width == 0 {
+		opts.width = 80
+	}
+
+	r, err := schemeReference(strings.Join(fs.Args(), " "), *scheme)
+	if err != nil {
+		log.Fatal(err)
+	}
+	loadEnhancedDataQuiet()
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	out.WriteString(renderRange(r, opts))
+}
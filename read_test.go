@@ -0,0 +1,61 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		text           string
+		width          int
+		prefix, indent string
+		lines          []string
+	}{
+		{"", 20, "", "", []string{""}},
+		{"Jesus wept.", 0, "", "", []string{"Jesus wept."}},
+		{"In the beginning God created the heaven and the earth.", 20, "", "",
+			[]string{"In the beginning God", "created the heaven", "and the earth."}},
+		{"In the beginning God created the heaven and the earth.", 24, "   1 ", "     ",
+			[]string{"   1 In the beginning", "     God created the", "     heaven and the", "     earth."}},
+		{"Thy word is a lamp unto my feet", 10, "", "  ",
+			[]string{"Thy word", "  is a", "  lamp", "  unto my", "  feet"}},
+		{"Unbreakable", 5, "", "", []string{"Unbreakable"}},
+		{"name’s sake", 11, "", "", []string{"name’s sake"}},
+	}
+	for _, test := range tests {
+		if got := wrapText(test.text, test.width, test.prefix, test.indent); !reflect.DeepEqual(got, test.lines) {
+			t.Errorf("wrapText(%q, %d) = %q, expecting %q", test.text, test.width, got, test.lines)
+		}
+	}
+}
+
+func TestRenderRange(t *testing.T) {
+	useDisplayLocale(t, "en")
+	chapter := &ChapterEnhanced{Nb: 1, Summary: "Naomi goes to Moab.", Verses: []*VerseEnhanced{
+		roundTripVerse("Ruth", 1, 1, "Naomi and Ruth", "Now it came to pass in the days when the judges ruled."),
+		roundTripVerse("Ruth", 1, 2, "", "And the name of the man was Elimelech.", Span{Start: 24, End: 27, Style: SpanSupplied}),
+	}}
+	useBooks(t, []*BookEnhanced{{Title: "Ruth", Chapters: []*ChapterEnhanced{chapter}}})
+	r, err := parseReference("Ruth 1:1-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		opts readOptions
+		want string
+	}{
+		{readOptions{width: 40}, "RUTH 1\n\nNaomi and Ruth\n\n" +
+			"1 Now it came to pass in the days when\nthe judges ruled. 2 And the name of the\nman was Elimelech.\n"},
+		{readOptions{width: 40, margin: true}, "RUTH 1\n\nNaomi and Ruth\n\n" +
+			"   1 Now it came to pass in the days\n     when the judges ruled.\n" +
+			"   2 And the name of the man was\n     Elimelech.\n"},
+		{readOptions{markdown: true}, "## Ruth 1\n\n> Naomi goes to Moab.\n\n### Naomi and Ruth\n\n" +
+			"**1** Now it came to pass in the days when the judges ruled. **2** And the name of the man *was* Elimelech.\n"},
+	}
+	for _, test := range tests {
+		if got := renderRange(r, test.opts); got != test.want {
+			t.Errorf("renderRange(%+v) =\n%s\nexpecting\n%s", test.opts, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var redLetterPath = "./json/red-letter.tsv"
+
+// redLetterRanges are verses marked by the fallback list, the text being the
+// marked words or "" when the whole verse is spoken by Jesus.
+var redLetterRanges = make(map[VerseID][]string)
+
+// loadRedLetters reads the fallback red letter list, one verse or range per
+// line with optionally the exact words spoken when it's not the whole verse:
+// "Matt.5.3-Matt.7.27" or "Luke 23:43	Verily I say unto thee".
+func loadRedLetters(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("failed to open red letter list %s: %w", file, err)
+	}
+	defer f.Close()
+
+	fmt.Println("reading red letter list...")
+	scanner := bufio.NewScanner(f)
+	lineNb := 0
+	for scanner.Scan() {
+		lineNb++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		cols := strings.SplitN(line, "\t", 2)
+		r, err := parseCrossRefTarget(cols[0])
+		if err != nil {
+			logError(fmt.Errorf("%s:%d: %w", file, lineNb, err))
+			continue
+		}
+		words := ""
+		if len(cols) == 2 {
+			words = strings.TrimSpace(cols[1])
+		}
+		for _, id := range r.IDs() {
+			redLetterRanges[id] = append(redLetterRanges[id], words)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read red letter list %s: %w", file, err)
+	}
+	fmt.Printf("read red letters for %v verses\n", len(redLetterRanges))
+	return nil
+}
+
+// applyRedLetters marks the verses of the fallback list that didn't get any
+// red letter span from the scraped pages.
+func applyRedLetters() {
+	count := 0
+	for id, words := range redLetterRanges {
+		v, ok := enhancedVerses[id]
+		if !ok {
+			logError(fmt.Errorf("red letter verse not found: %s", id))
+			continue
+		}
+		if hasSpanStyle(v, SpanWordsOfJesus) {
+			continue
+		}
+		for _, w := range words {
+			if w == "" {
+				addSpan(v, SpanWordsOfJesus, 0, len(v.Text))
+				continue
+			}
+			i := strings.Index(v.Text, w)
+			if i == -1 {
+				logError(fmt.Errorf("red letter words not found in %s: %s", id, w))
+				continue
+			}
+			addSpan(v, SpanWordsOfJesus, i, i+len(w))
+		}
+		count++
+	}
+	fmt.Printf("applied red letters to %v verses!\n", count)
+}
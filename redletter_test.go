@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyRedLetters(t *testing.T) {
+	whole := roundTripVerse("Matthew", 5, 3, "", "Blessed are the poor in spirit: for theirs is the kingdom of heaven.")
+	words := roundTripVerse("Luke", 23, 43, "", "And Jesus said unto him, Verily I say unto thee, To day shalt thou be with me in paradise.")
+	scraped := roundTripVerse("Matthew", 5, 4, "", "Blessed are they that mourn: for they shall be comforted.",
+		Span{Start: 0, End: 8, Style: SpanWordsOfJesus})
+	useBooks(t, []*BookEnhanced{{Title: "Matthew", Chapters: []*ChapterEnhanced{{Nb: 5, Verses: []*VerseEnhanced{whole, scraped}}}},
+		{Title: "Luke", Chapters: []*ChapterEnhanced{{Nb: 23, Verses: []*VerseEnhanced{words}}}}})
+	saved := redLetterRanges
+	defer func() { redLetterRanges = saved }()
+	redLetterRanges = map[VerseID][]string{
+		whole.ID:   {""},
+		words.ID:   {"Verily I say unto thee, To day shalt thou be with me in paradise."},
+		scraped.ID: {""},
+	}
+
+	applyRedLetters()
+	tests := []struct {
+		v          *VerseEnhanced
+		start, end int
+	}{
+		{whole, 0, len(whole.Text)},
+		{words, 25, len(words.Text)},
+		{scraped, 0, 8},
+	}
+	for _, test := range tests {
+		if len(test.v.Spans) != 1 || test.v.Spans[0].Start != test.start || test.v.Spans[0].End != test.end {
+			t.Errorf("%s spans are %v, expecting %d-%d", test.v.ID, test.v.Spans, test.start, test.end)
+		}
+	}
+}
+
+func TestShippedRedLetters(t *testing.T) {
+	useBooks(t, nil)
+	saved := redLetterRanges
+	defer func() { redLetterRanges = saved }()
+	redLetterRanges = make(map[VerseID][]string)
+	loadEnhancedBooks()
+	if err := loadRedLetters(redLetterPath); err != nil {
+		t.Fatal(err)
+	}
+	if len(redLetterRanges) == 0 {
+		t.Fatal("no red letter verse in the shipped list")
+	}
+	for id, list := range redLetterRanges {
+		v, ok := enhancedVerses[id]
+		if !ok {
+			t.Errorf("red letter verse not found: %s", id)
+			continue
+		}
+		for _, words := range list {
+			if !strings.Contains(v.Text, words) {
+				t.Errorf("red letter words not found in %s: %s", id, words)
+			}
+		}
+	}
+}
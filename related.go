@@ -0,0 +1,362 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math"
+	"os"
+	"strings"
+)
+
+var relatedPath = "./json/related.json"
+
+const relatedTop = 10
+
+// relatedVersion is bumped when the scoring changes, so the saved indexes are rebuilt.
+const relatedVersion = 1
+
+// stopWords are left out of the similarity index, the KJV pronouns and
+// auxiliaries included.
+var stopWords = makeSet(strings.Fields(`a about after again against all also am an and any are art as at be
+	because been before being but by came come did do doth dost even for from had hast hath have he her hers him
+	himself his how i if in into is it its let me mine my no nor not now o of on one or our out own said saith
+	say shall shalt she so than that the thee their them themselves then there therefore thereof these they thine
+	this those thou through thus thy thyself to unto up upon us was we were what when whence where wherefore which
+	while who whom whose why will wilt with ye yea yes yet you your does has says`))
+
+func makeSet(words []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
+
+// stemSuffixes are stripped in order, the first matching one wins.
+var stemSuffixes = []string{"eth", "est", "ing", "edst", "ed", "ies", "es", "s", "ly"}
+
+// stem reduces a word to a crude root, "loveth", "loved" and "loves" giving "lov".
+func stem(word string) string {
+	word = strings.TrimSuffix(strings.TrimSuffix(word, "'s"), "’s")
+	for _, suffix := range stemSuffixes {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= 3 {
+			word = strings.TrimSuffix(word, suffix)
+			if suffix == "ies" {
+				word += "y"
+			}
+			break
+		}
+	}
+	return strings.TrimSuffix(word, "e")
+}
+
+// indexTerms gives the terms of a text in their modern form, without stop
+// words and stemmed.
+func indexTerms(text string) []string {
+	var terms []string
+	for _, word := range searchWords(text, true) {
+		if stopWords[word] || len(word) < 2 {
+			continue
+		}
+		terms = append(terms, stem(word))
+	}
+	return terms
+}
+
+type RelatedVerse struct {
+	Ref   VerseRange `json:"ref"`
+	Score float64    `json:"score"`
+}
+
+// RelatedIndex holds the most similar verses and sections, the sections
+// being keyed by their first verse. Hash identifies the text and scoring the
+// index was built from.
+type RelatedIndex struct {
+	Hash     string                     `json:"hash"`
+	Verses   map[VerseID][]RelatedVerse `json:"verses"`
+	Sections map[VerseID][]RelatedVerse `json:"sections"`
+}
+
+var relatedIndex *RelatedIndex
+
+type similarityDoc struct {
+	ref     VerseRange
+	weights map[string]float64
+}
+
+type posting struct {
+	doc    int
+	weight float64
+}
+
+// similarityIndex is a tf-idf index over documents, the vectors being normalized
+// so the dot product is the cosine similarity.
+type similarityIndex struct {
+	docs     []similarityDoc
+	postings map[string][]posting
+}
+
+func newSimilarityIndex(refs []VerseRange, texts []string) *similarityIndex {
+	idx := &similarityIndex{postings: make(map[string][]posting)}
+	df := make(map[string]int)
+	var tfs []map[string]int
+	for _, text := range texts {
+		tf := make(map[string]int)
+		for _, term := range indexTerms(text) {
+			tf[term]++
+		}
+		for term := range tf {
+			df[term]++
+		}
+		tfs = append(tfs, tf)
+	}
+	for i, tf := range tfs {
+		doc := similarityDoc{ref: refs[i], weights: make(map[string]float64)}
+		norm := 0.0
+		for term, n := range tf {
+			w := (1 + math.Log(float64(n))) * math.Log(float64(len(texts))/float64(df[term]))
+			doc.weights[term] = w
+			norm += w * w
+		}
+		norm = math.Sqrt(norm)
+		for term, w := range doc.weights {
+			if norm > 0 {
+				doc.weights[term] = w / norm
+			}
+			idx.postings[term] = append(idx.postings[term], posting{doc: i, weight: doc.weights[term]})
+		}
+		idx.docs = append(idx.docs, doc)
+	}
+	return idx
+}
+
+// similar returns the n documents closest to the document i, scores being
+// a buffer of one score per document, left zeroed.
+func (idx *similarityIndex) similar(i, n int, scores []float64) []RelatedVerse {
+	var touched []int
+	for term, w := range idx.docs[i].weights {
+		for _, p := range idx.postings[term] {
+			if p.doc == i || w*p.weight == 0 {
+				continue
+			}
+			if scores[p.doc] == 0 {
+				touched = append(touched, p.doc)
+			}
+			scores[p.doc] += w * p.weight
+		}
+	}
+	var related []RelatedVerse
+	for _, doc := range touched {
+		rel := RelatedVerse{Ref: idx.docs[doc].ref, Score: math.Round(scores[doc]*1000) / 1000}
+		scores[doc] = 0
+		if len(related) == n && !rel.before(related[n-1]) {
+			continue
+		}
+		if len(related) < n {
+			related = append(related, rel)
+		} else {
+			related[n-1] = rel
+		}
+		for j := len(related) - 1; j > 0 && related[j].before(related[j-1]); j-- {
+			related[j], related[j-1] = related[j-1], related[j]
+		}
+	}
+	return related
+}
+
+// before orders by descending score, then by reference.
+func (r RelatedVerse) before(other RelatedVerse) bool {
+	if r.Score != other.Score {
+		return r.Score > other.Score
+	}
+	return r.Ref.Start < other.Ref.Start
+}
+
+// relatedHash hashes the verses and section titles of the enhanced books with
+// the version and size of the index, a saved index with another hash being stale.
+func relatedHash(n int) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "%d %d\n", relatedVersion, n)
+	for _, book := range enhanced {
+		for _, c := range book.Chapters {
+			for _, v := range c.Verses {
+				fmt.Fprintf(h, "%d %s\n", v.ID, v.Text)
+			}
+		}
+	}
+	for _, s := range flatSections() {
+		fmt.Fprintf(h, "%d %d %s\n", s.start, s.end, s.title)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// buildRelatedIndex compares every verse and every titled section with the others.
+func buildRelatedIndex(n int) *RelatedIndex {
+	index := &RelatedIndex{
+		Hash:     relatedHash(n),
+		Verses:   make(map[VerseID][]RelatedVerse),
+		Sections: make(map[VerseID][]RelatedVerse),
+	}
+
+	var refs []VerseRange
+	var texts []string
+	for _, book := range enhanced {
+		for _, c := range book.Chapters {
+			for _, v := range c.Verses {
+				refs = append(refs, VerseRange{Start: v.ID, End: v.ID})
+				texts = append(texts, v.Text)
+			}
+		}
+	}
+	verses := newSimilarityIndex(refs, texts)
+	scores := make([]float64, len(verses.docs))
+	for i, doc := range verses.docs {
+		index.Verses[doc.ref.Start] = verses.similar(i, n, scores)
+	}
+
+	refs, texts = nil, nil
+	for _, s := range flatSections() {
+		if s.title == "" {
+			continue
+		}
+		r := VerseRange{Start: s.start, End: s.end}
+		var b strings.Builder
+		b.WriteString(s.title)
+		for _, id := range r.IDs() {
+			if v, ok := enhancedVerses[id]; ok {
+				b.WriteString(" " + v.Text)
+			}
+		}
+		refs = append(refs, r)
+		texts = append(texts, b.String())
+	}
+	sections := newSimilarityIndex(refs, texts)
+	scores = make([]float64, len(sections.docs))
+	for i, doc := range sections.docs {
+		index.Sections[doc.ref.Start] = sections.similar(i, n, scores)
+	}
+	return index
+}
+
+func writeRelatedIndex(file string, index *RelatedIndex) {
+	bytes, err := json.Marshal(index)
+	if err != nil {
+		panic(err)
+	}
+	if err := ioutil.WriteFile(file, bytes, 0777); err != nil {
+		panic(err)
+	}
+	fmt.Println("wrote file: ", file)
+}
+
+func loadRelatedIndex(file string) error {
+	bytes, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	index := new(RelatedIndex)
+	if err := json.Unmarshal(bytes, index); err != nil {
+		return fmt.Errorf("failed to read related index %s: %w", file, err)
+	}
+	relatedIndex = index
+	return nil
+}
+
+// ensureRelatedIndex loads the index from disk, building and saving it when
+// missing or built from other verses.
+func ensureRelatedIndex() {
+	if relatedIndex != nil {
+		return
+	}
+	if err := loadRelatedIndex(relatedPath); err == nil {
+		if relatedIndex.Hash == relatedHash(relatedTop) {
+			return
+		}
+		fmt.Println("related index is stale, rebuilding...")
+	}
+	relatedIndex = buildRelatedIndex(relatedTop)
+	writeRelatedIndex(relatedPath, relatedIndex)
+}
+
+// RelatedVerses returns the n verses most similar to the first verse of the reference.
+func RelatedVerses(ref string, n int) ([]RelatedVerse, error) {
+	r, err := parseReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	ensureRelatedIndex()
+	related := relatedIndex.Verses[r.Start]
+	if len(related) > n {
+		related = related[:n]
+	}
+	return related, nil
+}
+
+// RelatedSections returns the n sections most similar to the section holding the reference.
+func RelatedSections(ref string, n int) ([]RelatedVerse, error) {
+	r, err := parseReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	ensureRelatedIndex()
+	for _, s := range flatSections() {
+		if s.title != "" && r.Start >= s.start && r.Start <= s.end {
+			related := relatedIndex.Sections[s.start]
+			if len(related) > n {
+				related = related[:n]
+			}
+			return related, nil
+		}
+	}
+	return nil, fmt.Errorf("%s isn't in a titled section", ref)
+}
+
+func relatedCommand(args []string) {
+	fs := flag.NewFlagSet("related", flag.ExitOnError)
+	n := fs.Int("n", 5, "number of related verses or sections")
+	sections := fs.Bool("sections", false, "compare the sections instead of the verses")
+	build := fs.Bool("build", false, "rebuild the index")
+	index := fs.String("index", relatedPath, "index file, built when missing")
+	_ = fs.Parse(args)
+	relatedPath = *index
+	if fs.NArg() == 0 && !*build {
+		fmt.Fprintln(os.Stderr, "usage: related [-n 5] [-sections] [-build] <reference>")
+		os.Exit(2)
+	}
+
+	loadEnhancedDataQuiet()
+	if *build {
+		relatedIndex = buildRelatedIndex(relatedTop)
+		writeRelatedIndex(relatedPath, relatedIndex)
+		if fs.NArg() == 0 {
+			return
+		}
+	}
+	ref := strings.Join(fs.Args(), " ")
+	lookup := RelatedVerses
+	if *sections {
+		lookup = RelatedSections
+	}
+	related, err := lookup(ref, *n)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	for _, rel := range related {
+		text := ""
+		if v, ok := enhancedVerses[rel.Ref.Start]; ok {
+			text = v.Text
+			if *sections {
+				text = v.Title
+			}
+		}
+		fmt.Fprintf(out, "%.3f  %s  %s\n", rel.Score, rel.Ref, text)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		word, want string
+	}{
+		{"loveth", "lov"},
+		{"loved", "lov"},
+		{"loves", "lov"},
+		{"love", "lov"},
+		{"lord's", "lord"},
+		{"cities", "city"},
+		{"lovedst", "lov"},
+		{"goes", "go"},
+		{"sheep", "sheep"},
+		{"is", "is"},
+	}
+	for _, test := range tests {
+		if got := stem(test.word); got != test.want {
+			t.Errorf("stem(%q) = %q, expecting %q", test.word, got, test.want)
+		}
+	}
+}
+
+func TestIndexTerms(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"For God so loved the world", []string{"god", "lov", "world"}},
+		{"he that loveth not knoweth not God", []string{"lov", "know", "god"}},
+		{"The LORD’s house", []string{"lord", "hous"}},
+		{"Thou art my son", []string{"son"}},
+	}
+	for _, test := range tests {
+		if got := indexTerms(test.text); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("indexTerms(%q) = %q, expecting %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestSimilarityIndex(t *testing.T) {
+	refs := []VerseRange{
+		{Start: newVerseID(43, 3, 16), End: newVerseID(43, 3, 16)},
+		{Start: newVerseID(62, 4, 9), End: newVerseID(62, 4, 9)},
+		{Start: newVerseID(1, 1, 1), End: newVerseID(1, 1, 1)},
+		{Start: newVerseID(62, 4, 8), End: newVerseID(62, 4, 8)},
+	}
+	texts := []string{
+		"For God so loved the world, that he gave his only begotten Son",
+		"God sent his only begotten Son into the world, that we might live through him.",
+		"In the beginning God created the heaven and the earth.",
+		"He that loveth not knoweth not God; for God is love.",
+	}
+	idx := newSimilarityIndex(refs, texts)
+	scores := make([]float64, len(idx.docs))
+	related := idx.similar(0, 2, scores)
+	if len(related) != 2 || related[0].Ref != refs[1] || related[1].Ref != refs[3] {
+		t.Fatalf("John 3:16 is closest to %v, expecting 1 John 4:9 then 1 John 4:8", related)
+	}
+	if related[0].Score <= related[1].Score || related[0].Score > 1 {
+		t.Errorf("scores %v aren't decreasing within 0 and 1", related)
+	}
+	for i, s := range scores {
+		if s != 0 {
+			t.Errorf("score %d left at %v", i, s)
+		}
+	}
+	if related := idx.similar(2, 3, scores); len(related) != 0 {
+		t.Errorf("Genesis 1:1 only shares God, found in every text, yet is related to %v", related)
+	}
+}
+
+func TestRelatedVerses(t *testing.T) {
+	useBooks(t, []*BookEnhanced{
+		{Title: "Genesis", Chapters: []*ChapterEnhanced{{Nb: 1, Verses: []*VerseEnhanced{
+			roundTripVerse("Genesis", 1, 1, "The Creation", "In the beginning God created the heaven and the earth."),
+		}}}},
+		{Title: "John", Chapters: []*ChapterEnhanced{{Nb: 3, Verses: []*VerseEnhanced{
+			roundTripVerse("John", 3, 16, "God’s Love", "For God so loved the world, that he gave his only begotten Son"),
+			roundTripVerse("John", 3, 17, "", "For God sent not his Son into the world to condemn the world"),
+		}}}},
+		{Title: "1 John", Chapters: []*ChapterEnhanced{{Nb: 4, Verses: []*VerseEnhanced{
+			roundTripVerse("1 John", 4, 8, "God Is Love", "He that loveth not knoweth not God; for God is love."),
+			roundTripVerse("1 John", 4, 9, "", "God sent his only begotten Son into the world, that we might live through him."),
+		}}}},
+	})
+	saved := relatedIndex
+	defer func() { relatedIndex = saved }()
+	relatedIndex = buildRelatedIndex(2)
+	if relatedIndex.Hash != relatedHash(2) || relatedIndex.Hash == relatedHash(3) {
+		t.Error("the hash doesn't follow the size of the index")
+	}
+
+	related, err := RelatedVerses("John 3:16", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(related) != 1 || related[0].Ref.Start != newVerseID(62, 4, 9) {
+		t.Errorf("John 3:16 is related to %v, expecting 1 John 4:9", related)
+	}
+	sections, err := RelatedSections("1 John 4:9", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := VerseRange{Start: newVerseID(43, 3, 16), End: newVerseID(43, 3, 17)}
+	if len(sections) != 1 || sections[0].Ref != want {
+		t.Errorf("1 John 4:8-9 is related to %v, expecting %s", sections, want)
+	}
+	if _, err := RelatedVerses("Hezekiah 1:1", 1); err == nil {
+		t.Error("RelatedVerses of an unknown book gave no error")
+	}
+}
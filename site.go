@@ -0,0 +1,284 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//go:embed templates/site
+var siteTemplates embed.FS
+
+var sitePath = "./site"
+
+// siteAssets are copied as is to the site, the other templates are rendered.
+var siteAssets = []string{"style.css", "search.js"}
+
+type siteLink struct {
+	URL   string
+	Label string
+}
+
+type siteIndexBook struct {
+	Name     string
+	Slug     string
+	Chapters []int
+}
+
+type siteTestament struct {
+	Name  string
+	Books []siteIndexBook
+}
+
+type siteVerse struct {
+	Nb   int
+	OSIS string
+	HTML template.HTML
+}
+
+type siteParagraph struct {
+	Title         string
+	Anchor        string
+	StanzaHeading string
+	Poetry        bool
+	Verses        []siteVerse
+}
+
+type sitePage struct {
+	Lang         string
+	Title        string
+	Root         string
+	Testaments   []siteTestament
+	Book         *BookInfo
+	Chapter      *ChapterEnhanced
+	Introduction []string
+	Paragraphs   []siteParagraph
+	Prev         *siteLink
+	Next         *siteLink
+}
+
+// siteSearchEntry is one verse of the search index, the keys are kept short
+// since the whole index is downloaded by the browser.
+type siteSearchEntry struct {
+	Ref     string `json:"r"`
+	URL     string `json:"u"`
+	Text    string `json:"t"`
+	Section string `json:"s,omitempty"`
+}
+
+// loadSiteTemplates parses the default templates, then the ones of dir, if
+// any, so a file of dir replaces the default of the same name.
+func loadSiteTemplates(dir string) (fs.FS, *template.Template, error) {
+	files, err := fs.Sub(siteTemplates, "templates/site")
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl, err := template.ParseFS(files, "*.html")
+	if err != nil {
+		return nil, nil, err
+	}
+	if dir == "" {
+		return files, tmpl, nil
+	}
+	overrides, _ := filepath.Glob(filepath.Join(dir, "*.html"))
+	if len(overrides) > 0 {
+		if tmpl, err = tmpl.ParseFiles(overrides...); err != nil {
+			return nil, nil, err
+		}
+	}
+	return overrideFS{dir: dir, fallback: files}, tmpl, nil
+}
+
+// overrideFS reads the assets from the override directory when they exist there.
+type overrideFS struct {
+	dir      string
+	fallback fs.FS
+}
+
+func (o overrideFS) Open(name string) (fs.File, error) {
+	if f, err := os.Open(filepath.Join(o.dir, filepath.FromSlash(name))); err == nil {
+		return f, nil
+	}
+	return o.fallback.Open(name)
+}
+
+func chapterURL(info *BookInfo, chapter int) string {
+	return fmt.Sprintf("%s/%d.html", info.Slug(), chapter)
+}
+
+func siteParagraphs(chapter *ChapterEnhanced, poetic bool) []siteParagraph {
+	var paragraphs []siteParagraph
+	for i, v := range chapter.Verses {
+		if i == 0 || v.Title != "" || v.StanzaHeading != "" || v.Paragraph || v.Stanza {
+			p := siteParagraph{Title: v.Title, StanzaHeading: v.StanzaHeading, Poetry: poetic}
+			if v.Title != "" {
+				p.Anchor = fmt.Sprintf("s%d", v.Nb)
+			}
+			paragraphs = append(paragraphs, p)
+		}
+		p := &paragraphs[len(paragraphs)-1]
+		content := verseHTML(v)
+		if len(v.Lines) > 0 {
+			var lines []string
+			for _, line := range verseLines(v) {
+				lines = append(lines, `<span class="line">`+textHTML(line.Text, line.Spans)+`</span>`)
+			}
+			content = strings.Join(lines, "")
+		}
+		p.Verses = append(p.Verses, siteVerse{Nb: v.Nb, OSIS: v.OSIS, HTML: template.HTML(content)})
+	}
+	return paragraphs
+}
+
+func writeSitePage(tmpl *template.Template, name, file string, page *sitePage) {
+	if err := os.MkdirAll(filepath.Dir(file), 0777); err != nil {
+		panic(err)
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+	page.Lang = displayLanguage()
+	if err := tmpl.ExecuteTemplate(f, name, page); err != nil {
+		panic(fmt.Errorf("failed to render %s: %w", file, err))
+	}
+}
+
+// writeSite generates the static site for the enhanced books in dir.
+func writeSite(dir, templatesDir string) {
+	assets, tmpl, err := loadSiteTemplates(templatesDir)
+	if err != nil {
+		panic(err)
+	}
+	names, err := readBookNames()
+	if err != nil {
+		panic(err)
+	}
+	os.RemoveAll(dir)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		panic(err)
+	}
+
+	var books []*BookEnhanced
+	index := &sitePage{Title: "King James Version"}
+	for _, name := range names {
+		info := mustBookInfo(name)
+		book := getEnhancedBook(info.Name)
+		if book == nil {
+			logError(fmt.Errorf("book listed in %s isn't loaded: %s", booksFile, name))
+			continue
+		}
+		books = append(books, book)
+		if len(index.Testaments) == 0 || index.Testaments[len(index.Testaments)-1].Name != testamentName(info.Testament) {
+			index.Testaments = append(index.Testaments, siteTestament{Name: testamentName(info.Testament)})
+		}
+		ib := siteIndexBook{Name: info.DisplayName(), Slug: info.Slug()}
+		for _, c := range book.Chapters {
+			ib.Chapters = append(ib.Chapters, c.Nb)
+		}
+		t := &index.Testaments[len(index.Testaments)-1]
+		t.Books = append(t.Books, ib)
+	}
+	writeSitePage(tmpl, "index.html", filepath.Join(dir, "index.html"), index)
+	writeSitePage(tmpl, "search.html", filepath.Join(dir, "search.html"), &sitePage{Title: "Search"})
+
+	// flatten the chapters to link each one to its neighbours, across books
+	type siteChapter struct {
+		info    *BookInfo
+		book    *BookEnhanced
+		chapter *ChapterEnhanced
+	}
+	var chapters []siteChapter
+	for _, book := range books {
+		info := mustBookInfo(book.Title)
+		for _, c := range book.Chapters {
+			chapters = append(chapters, siteChapter{info: info, book: book, chapter: c})
+		}
+	}
+
+	var search []siteSearchEntry
+	for i, sc := range chapters {
+		page := &sitePage{
+			Title:      fmt.Sprintf("%s %d", sc.info.DisplayName(), sc.chapter.Nb),
+			Root:       "../",
+			Book:       sc.info,
+			Chapter:    sc.chapter,
+			Paragraphs: siteParagraphs(sc.chapter, sc.info.Poetic()),
+		}
+		if sc.chapter.Nb == 1 && sc.book.Introduction != "" {
+			for _, par := range strings.Split(sc.book.Introduction, "\n\n") {
+				if par = strings.TrimSpace(par); par != "" {
+					page.Introduction = append(page.Introduction, par)
+				}
+			}
+		}
+		if i > 0 {
+			prev := chapters[i-1]
+			page.Prev = &siteLink{URL: chapterURL(prev.info, prev.chapter.Nb), Label: fmt.Sprintf("%s %d", prev.info.DisplayName(), prev.chapter.Nb)}
+		}
+		if i < len(chapters)-1 {
+			next := chapters[i+1]
+			page.Next = &siteLink{URL: chapterURL(next.info, next.chapter.Nb), Label: fmt.Sprintf("%s %d", next.info.DisplayName(), next.chapter.Nb)}
+		}
+		url := chapterURL(sc.info, sc.chapter.Nb)
+		writeSitePage(tmpl, "chapter.html", filepath.Join(dir, filepath.FromSlash(url)), page)
+
+		section := ""
+		for _, v := range sc.chapter.Verses {
+			if v.Title != "" {
+				section = v.Title
+			}
+			search = append(search, siteSearchEntry{
+				Ref:     fmt.Sprintf("%s %d:%d", sc.info.DisplayName(), sc.chapter.Nb, v.Nb),
+				URL:     fmt.Sprintf("%s#v%d", url, v.Nb),
+				Text:    v.Text,
+				Section: section,
+			})
+		}
+	}
+
+	bytes, err := json.Marshal(search)
+	if err != nil {
+		panic(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "search-index.json"), bytes, 0777); err != nil {
+		panic(err)
+	}
+	for _, asset := range siteAssets {
+		bytes, err := fs.ReadFile(assets, asset)
+		if err != nil {
+			panic(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, asset), bytes, 0777); err != nil {
+			panic(err)
+		}
+	}
+	fmt.Printf("wrote %v chapters to %s!\n", len(chapters), dir)
+}
+
+func testamentName(t Testament) string {
+	if t == NewTestament {
+		return "New Testament"
+	}
+	return "Old Testament"
+}
+
+func siteCommand(args []string) {
+	flags := flag.NewFlagSet("site", flag.ExitOnError)
+	out := flags.String("out", sitePath, "output directory")
+	templates := flags.String("templates", "", "directory of templates and assets replacing the default ones")
+	lang := localeFlag(flags)
+	_ = flags.Parse(args)
+	useLocale(*lang)
+
+	loadEnhancedData()
+	writeSite(*out, *templates)
+}
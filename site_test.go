@@ -0,0 +1,39 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func useDisplayLocale(t *testing.T, code string) {
+	saved := displayLocale
+	t.Cleanup(func() { displayLocale = saved })
+	if err := setDisplayLocale(code); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSitePageLanguage(t *testing.T) {
+	_, tmpl, err := loadSiteTemplates("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, code := range []string{"", "fr", "de"} {
+		useDisplayLocale(t, code)
+		file := filepath.Join(t.TempDir(), "search.html")
+		writeSitePage(tmpl, "search.html", file, &sitePage{Title: "Search"})
+		bytes, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `<html lang="` + displayLanguage() + `">`
+		if !strings.Contains(string(bytes), want) {
+			t.Errorf("page in locale %q doesn't start with %s", code, want)
+		}
+	}
+	if displayLanguage() != "de" {
+		t.Errorf("displayLanguage() = %s, expecting de", displayLanguage())
+	}
+}
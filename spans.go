@@ -0,0 +1,318 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	SpanWordsOfJesus = "wj"
+	SpanSupplied     = "add"
+)
+
+// Span marks a styled part of a verse. Start and End are byte offsets into
+// the verse Text, End being exclusive. Style names follow the USFM character
+// markers so they can be written as is.
+type Span struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Style string `json:"style"`
+}
+
+// spanOrder is the nesting order used when spans of different styles overlap, outer first.
+var spanOrder = []string{SpanWordsOfJesus, SpanSupplied}
+var spanVerseNumbers = regexp.MustCompile(`(?:^|\s)\d+\s`)
+
+// spanSelectors lists, per style, the ways it shows up in the scraped pages.
+var spanSelectors = map[string][]string{
+	SpanWordsOfJesus: {
+		".post .woj",
+		".post .red-letter",
+		".post font[color=red]",
+		`.post span[style*="color: red"]`,
+		`.post span[style*="color:red"]`,
+	},
+	SpanSupplied: {
+		".post em",
+		".post i:not([class])",
+	},
+}
+
+// captureSpans marks the styled text found in the page on the chapter verses.
+func captureSpans(book *Book, chapter *Chapter, document *goquery.Document) {
+	chap := getEnhancedChapter(book.Book, chapter.Chapter)
+	if chap == nil {
+		panic(fmt.Errorf("failed to find chapter: %s - %s", book.Book, chapter.Chapter))
+	}
+	for _, style := range spanOrder {
+		var snippets []string
+		document.Find(strings.Join(spanSelectors[style], ", ")).Each(func(i int, s *goquery.Selection) {
+			if text := strings.TrimSpace(s.Text()); text != "" {
+				snippets = append(snippets, text)
+			}
+		})
+		if len(snippets) == 0 {
+			continue
+		}
+		count := markSnippets(chap.Verses, snippets, style)
+		fmt.Printf("marked %v %s spans for %s - %s, found %v in page\n", count, style, book.Book, chapter.Chapter, len(snippets))
+	}
+}
+
+func addSpan(v *VerseEnhanced, style string, start, end int) {
+	if start >= end {
+		return
+	}
+	for _, s := range v.Spans {
+		if s.Style == style && s.Start == start && s.End == end {
+			return
+		}
+	}
+	v.Spans = append(v.Spans, Span{Start: start, End: end, Style: style})
+	sort.Slice(v.Spans, func(i, j int) bool {
+		if v.Spans[i].Start != v.Spans[j].Start {
+			return v.Spans[i].Start < v.Spans[j].Start
+		}
+		return v.Spans[i].End > v.Spans[j].End
+	})
+}
+
+func hasSpanStyle(v *VerseEnhanced, style string) bool {
+	for _, s := range v.Spans {
+		if s.Style == style {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeVerseSpans copies the spans of the same verse of another KJV edition,
+// they replace the spans of the styles it has. The texts must be the same once
+// the imported one gets the default typography, but for the case as the pages
+// write LORD as Lord, false is returned otherwise.
+func mergeVerseSpans(v, src *VerseEnhanced) bool {
+	after, pos := normalizeTypography(src.Text, defaultTypography)
+	after, pos = trimTrailingSpace(after, pos)
+	if len(after) != len(v.Text) || !strings.EqualFold(after, v.Text) {
+		return false
+	}
+	for _, style := range spanOrder {
+		if !hasSpanStyle(src, style) {
+			continue
+		}
+		var spans []Span
+		for _, s := range v.Spans {
+			if s.Style != style {
+				spans = append(spans, s)
+			}
+		}
+		v.Spans = spans
+		for _, s := range src.Spans {
+			if s.Style == style {
+				addSpan(v, style, pos[s.Start], pos[s.End])
+			}
+		}
+	}
+	return true
+}
+
+// markSnippets finds each snippet, in order, in the verses of a chapter and marks it
+// with style. Snippets taken from the page may run over several verses so they
+// are split on the verse numbers they contain.
+func markSnippets(verses []*VerseEnhanced, snippets []string, style string) int {
+	count := 0
+	verse, offset := 0, 0
+	for _, snippet := range snippets {
+		snippet = normalizeText(snippet) // same typography as the verse text
+		for _, piece := range spanVerseNumbers.Split(snippet, -1) {
+			piece = strings.TrimSpace(piece)
+			if piece == "" {
+				continue
+			}
+			v, i := findInVerses(verses, piece, verse, offset)
+			if v == -1 {
+				continue
+			}
+			addSpan(verses[v], style, i, i+len(piece))
+			verse, offset = v, i+len(piece)
+			count++
+		}
+	}
+	return count
+}
+
+// indexWords finds piece in text from offset, only where it doesn't start or end in the middle of a word.
+func indexWords(text, piece string, offset int) int {
+	for offset <= len(text) {
+		i := strings.Index(text[offset:], piece)
+		if i == -1 {
+			return -1
+		}
+		start, end := offset+i, offset+i+len(piece)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if !(start > 0 && unicode.IsLetter(before)) && !(end < len(text) && unicode.IsLetter(after)) {
+			return start
+		}
+		offset = start + 1
+	}
+	return -1
+}
+
+type spanSegment struct {
+	text   string
+	styles []string
+}
+
+// spanSegments cuts the text at every span boundary and lists the styles active on each part.
+func spanSegments(text string, spans []Span) []spanSegment {
+	bounds := []int{0, len(text)}
+	for _, s := range spans {
+		bounds = append(bounds, s.Start, s.End)
+	}
+	sort.Ints(bounds)
+
+	var segments []spanSegment
+	for i := 0; i < len(bounds)-1; i++ {
+		start, end := bounds[i], bounds[i+1]
+		if start == end || start < 0 || end > len(text) {
+			continue
+		}
+		seg := spanSegment{text: text[start:end]}
+		for _, style := range spanOrder {
+			for _, s := range spans {
+				if s.Style == style && s.Start <= start && s.End >= end {
+					seg.styles = append(seg.styles, style)
+					break
+				}
+			}
+		}
+		segments = append(segments, seg)
+	}
+	return segments
+}
+
+var spanHTMLTags = map[string][2]string{
+	SpanWordsOfJesus: {`<span class="wj">`, `</span>`},
+	SpanSupplied:     {`<em class="add">`, `</em>`},
+}
+
+// renderSpans writes the text with the markup of its spans, keeping outer
+// styles open while the inner ones change so the markup nests properly.
+func renderSpans(text string, spans []Span, open, close func(style string, depth int) string, escape func(string) string) string {
+	var b strings.Builder
+	var stack []string
+	for _, seg := range spanSegments(text, spans) {
+		keep := 0
+		for keep < len(stack) && keep < len(seg.styles) && stack[keep] == seg.styles[keep] {
+			keep++
+		}
+		for i := len(stack) - 1; i >= keep; i-- {
+			b.WriteString(close(stack[i], i))
+		}
+		stack = stack[:keep]
+		for i := keep; i < len(seg.styles); i++ {
+			b.WriteString(open(seg.styles[i], i))
+			stack = append(stack, seg.styles[i])
+		}
+		b.WriteString(escape(seg.text))
+	}
+	for i := len(stack) - 1; i >= 0; i-- {
+		b.WriteString(close(stack[i], i))
+	}
+	return b.String()
+}
+
+// verseHTML renders the verse text with its spans as escaped html.
+func verseHTML(v *VerseEnhanced) string {
+	return textHTML(v.Text, v.Spans)
+}
+
+func textHTML(text string, spans []Span) string {
+	return renderSpans(text, spans,
+		func(style string, depth int) string { return spanHTMLTags[style][0] },
+		func(style string, depth int) string { return spanHTMLTags[style][1] },
+		html.EscapeString)
+}
+
+// verseUSFM renders the verse text with its spans as USFM character markers,
+// using the + prefix for nested markers.
+func verseUSFM(v *VerseEnhanced) string {
+	return textUSFM(v.Text, v.Spans)
+}
+
+func textUSFM(text string, spans []Span) string {
+	marker := func(style string, depth int) string {
+		if depth > 0 {
+			return `\+` + style
+		}
+		return `\` + style
+	}
+	return renderSpans(text, spans,
+		func(style string, depth int) string { return marker(style, depth) + " " },
+		func(style string, depth int) string { return marker(style, depth) + "*" },
+		func(s string) string { return s })
+}
+
+// mergeCommand takes the words of Jesus, the supplied words and the psalm
+// superscriptions from an edition of the KJV in one of the import formats.
+func mergeCommand(args []string) {
+	flags := flag.NewFlagSet("merge", flag.ExitOnError)
+	format := flags.String("format", "", "osis, usfm, zefania or opensong, guessed from the file extension when empty")
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		log.Fatal("usage: merge [-format osis|usfm|zefania|opensong] <kjv file or usfm directory>")
+	}
+	file := flags.Arg(0)
+	if *format == "" {
+		*format = guessImportFormat(file)
+	}
+	importer, ok := importers[*format]
+	if !ok {
+		log.Fatalf("unknown format: %s, expecting osis, usfm, zefania or opensong", *format)
+	}
+	books, err := importer(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	loadEnhancedData()
+
+	merged, titles, differences := 0, 0, 0
+	for _, book := range books {
+		for _, c := range book.Chapters {
+			chap := getEnhancedChapter(book.Title, strconv.Itoa(c.Nb))
+			if chap != nil && chap.Superscription == "" && c.Superscription != "" {
+				chap.Superscription = normalizeText(c.Superscription)
+				titles++
+			}
+			for _, v := range c.Verses {
+				target, ok := enhancedVerses[v.ID]
+				if !ok || len(v.Spans) == 0 {
+					continue
+				}
+				if !mergeVerseSpans(target, v) {
+					logError(fmt.Errorf("merge: %s differs: %s", v.ID, v.Text))
+					differences++
+					continue
+				}
+				merged++
+			}
+		}
+	}
+	fmt.Printf("merged the spans of %v verses and %v superscriptions from %s\n", merged, titles, file)
+	if differences > 0 {
+		fmt.Printf("skipped %v verses whose text differs, see %s\n", differences, logFile)
+	}
+	writeEnhancedBooks(os.Stdout)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeVerseSpans(t *testing.T) {
+	tests := []struct {
+		text, src string
+		spans     []Span
+		srcSpans  []Span
+		merged    bool
+		want      []Span
+	}{
+		{
+			"And the earth was without form, and void; and darkness was upon the face of the deep.",
+			"And the earth was without form, and void; and darkness was upon the face of the deep. ",
+			nil, []Span{{Start: 55, End: 58, Style: SpanSupplied}},
+			true, []Span{{Start: 55, End: 58, Style: SpanSupplied}},
+		},
+		{
+			"Lord, how are they increased that trouble me! many are they that rise up against me.",
+			"LORD, how are they increased that trouble me! many are they that rise up against me.",
+			nil, []Span{{Start: 51, End: 54, Style: SpanSupplied}},
+			true, []Span{{Start: 51, End: 54, Style: SpanSupplied}},
+		},
+		{
+			"Jesus saith, ’Tis my Father’s will.",
+			"Jesus saith, 'Tis my Father's will.",
+			[]Span{{Start: 0, End: 37, Style: SpanWordsOfJesus}}, []Span{{Start: 13, End: 35, Style: SpanWordsOfJesus}},
+			true, []Span{{Start: 13, End: 39, Style: SpanWordsOfJesus}},
+		},
+		{
+			"In the beginning God created the heaven and the earth.",
+			"In the beginning God created the heavens and the earth.",
+			nil, []Span{{Start: 0, End: 5, Style: SpanSupplied}},
+			false, nil,
+		},
+	}
+	for _, test := range tests {
+		v := roundTripVerse("Genesis", 1, 1, "", test.text, test.spans...)
+		src := roundTripVerse("Genesis", 1, 1, "", test.src, test.srcSpans...)
+		if merged := mergeVerseSpans(v, src); merged != test.merged {
+			t.Errorf("mergeVerseSpans(%q) = %v, expecting %v", test.src, merged, test.merged)
+		}
+		if !reflect.DeepEqual(v.Spans, test.want) {
+			t.Errorf("mergeVerseSpans(%q) spans are %v, expecting %v", test.src, v.Spans, test.want)
+		}
+	}
+}
+
+func TestRenderSpans(t *testing.T) {
+	text := "He said, Come and see & follow."
+	tests := []struct {
+		spans      []Span
+		html, usfm string
+	}{
+		{nil, "He said, Come and see &amp; follow.", "He said, Come and see & follow."},
+		{
+			[]Span{{Start: 9, End: 31, Style: SpanWordsOfJesus}},
+			`He said, <span class="wj">Come and see &amp; follow.</span>`,
+			`He said, \wj Come and see & follow.\wj*`,
+		},
+		{
+			[]Span{{Start: 9, End: 31, Style: SpanWordsOfJesus}, {Start: 14, End: 21, Style: SpanSupplied}},
+			`He said, <span class="wj">Come <em class="add">and see</em> &amp; follow.</span>`,
+			`He said, \wj Come \+add and see\+add* & follow.\wj*`,
+		},
+		{
+			[]Span{{Start: 3, End: 13, Style: SpanSupplied}, {Start: 9, End: 31, Style: SpanWordsOfJesus}},
+			`He <em class="add">said, </em><span class="wj"><em class="add">Come</em> and see &amp; follow.</span>`,
+			`He \add said, \add*\wj \+add Come\+add* and see & follow.\wj*`,
+		},
+	}
+	for _, test := range tests {
+		if got := textHTML(text, test.spans); got != test.html {
+			t.Errorf("textHTML(%v) = %s, expecting %s", test.spans, got, test.html)
+		}
+		if got := textUSFM(text, test.spans); got != test.usfm {
+			t.Errorf("textUSFM(%v) = %s, expecting %s", test.spans, got, test.usfm)
+		}
+	}
+}
+
+func TestIndexWords(t *testing.T) {
+	tests := []struct {
+		text, piece string
+		offset, i   int
+	}{
+		{"the man and the woman", "man", 0, 4},
+		{"the woman and the man", "man", 0, 18},
+		{"the manna", "man", 0, -1},
+		{"the man", "man", 5, -1},
+		{"the Lord’s house", "Lord", 0, 4},
+		{"he said, Follow me", "said, Follow", 0, 3},
+	}
+	for _, test := range tests {
+		if got := indexWords(test.text, test.piece, test.offset); got != test.i {
+			t.Errorf("indexWords(%q, %q, %d) = %d, expecting %d", test.text, test.piece, test.offset, got, test.i)
+		}
+	}
+}
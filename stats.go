@@ -0,0 +1,354 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var statsPath = "./stats"
+
+// enhancedStatsFile is written next to the enhanced books by writeEnhancedBooks.
+var enhancedStatsFile = "stats.json"
+
+const readingWordsPerMinute = 200
+
+// WordEntry is one word of the concordance with every verse it appears in,
+// a verse being listed once per occurrence.
+type WordEntry struct {
+	Word       string         `json:"word"`
+	Count      int            `json:"count"`
+	Testaments map[string]int `json:"testaments"`
+	Books      map[string]int `json:"books"`
+	Refs       []VerseID      `json:"refs"`
+}
+
+type BookVocabulary struct {
+	Book       string `json:"book"`
+	Words      int    `json:"words"`
+	Vocabulary int    `json:"vocabulary"`
+	Unique     int    `json:"unique"`
+	Hapax      int    `json:"hapax"`
+}
+
+type NGram struct {
+	N     int    `json:"n"`
+	Text  string `json:"text"`
+	Count int    `json:"count"`
+}
+
+// TextStats counts a run of verses, the reading time is estimated at readingWordsPerMinute.
+type TextStats struct {
+	Verses         int     `json:"verses"`
+	Words          int     `json:"words"`
+	Characters     int     `json:"characters"`
+	ReadingMinutes float64 `json:"readingMinutes"`
+}
+
+type SectionStats struct {
+	Title string     `json:"title"`
+	Range VerseRange `json:"range"`
+	TextStats
+}
+
+type ChapterStats struct {
+	Nb int `json:"nb"`
+	TextStats
+	Sections []*SectionStats `json:"sections,omitempty"`
+}
+
+type BookStats struct {
+	Book string `json:"book"`
+	TextStats
+	Chapters []*ChapterStats `json:"chapters"`
+}
+
+type EnhancedStats struct {
+	WordsPerMinute int `json:"wordsPerMinute"`
+	TextStats
+	Books []*BookStats `json:"books"`
+}
+
+type Concordance struct {
+	Words map[string]*WordEntry
+	Books []*BookVocabulary
+}
+
+// textWords splits a verse in lower cased words, the apostrophes and hyphens
+// within a word are kept, "LORD’s" and "LORD's" both giving "lord's".
+func textWords(text string) []string {
+	var words []string
+	var b strings.Builder
+	flush := func() {
+		if b.Len() > 0 {
+			words = append(words, strings.Trim(b.String(), "'-"))
+			b.Reset()
+		}
+	}
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToLower(r))
+		case r == '\'' || r == '’':
+			if b.Len() > 0 {
+				b.WriteRune('\'')
+			}
+		case r == '-' && b.Len() > 0:
+			b.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
+
+// buildConcordance counts the words of every verse, in their modern form when modern is set.
+func buildConcordance(modern bool) *Concordance {
+	c := &Concordance{Words: make(map[string]*WordEntry)}
+	books := make(map[string]*BookVocabulary)
+	for _, book := range enhanced {
+		info := mustBookInfo(book.Title)
+		vocabulary := &BookVocabulary{Book: info.DisplayName()}
+		seen := make(map[string]bool)
+		for _, chapter := range book.Chapters {
+			for _, v := range chapter.Verses {
+				for _, word := range searchWords(v.Text, modern) {
+					entry, ok := c.Words[word]
+					if !ok {
+						entry = &WordEntry{Word: word, Testaments: make(map[string]int), Books: make(map[string]int)}
+						c.Words[word] = entry
+					}
+					entry.Count++
+					entry.Testaments[info.Testament.String()]++
+					entry.Books[info.OSIS]++
+					entry.Refs = append(entry.Refs, v.ID)
+					vocabulary.Words++
+					seen[word] = true
+				}
+			}
+		}
+		vocabulary.Vocabulary = len(seen)
+		books[info.OSIS] = vocabulary
+		c.Books = append(c.Books, vocabulary)
+	}
+	// the words found in a single book
+	for _, entry := range c.Words {
+		if len(entry.Books) != 1 {
+			continue
+		}
+		for osis := range entry.Books {
+			books[osis].Unique++
+			if entry.Count == 1 {
+				books[osis].Hapax++
+			}
+		}
+	}
+	return c
+}
+
+// sortedWords lists the words by descending count, then alphabetically.
+func (c *Concordance) sortedWords() []*WordEntry {
+	var entries []*WordEntry
+	for _, entry := range c.Words {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return entries[i].Word < entries[j].Word
+	})
+	return entries
+}
+
+// hapaxLegomena lists the words found once in the whole text.
+func (c *Concordance) hapaxLegomena() []*WordEntry {
+	var hapax []*WordEntry
+	for _, entry := range c.Words {
+		if entry.Count == 1 {
+			hapax = append(hapax, entry)
+		}
+	}
+	sort.Slice(hapax, func(i, j int) bool { return hapax[i].Word < hapax[j].Word })
+	return hapax
+}
+
+// topNGrams counts the sequences of n words within the verses and returns the top most frequent.
+func topNGrams(n, top int, modern bool) []NGram {
+	counts := make(map[string]int)
+	for _, v := range enhancedVerses {
+		words := searchWords(v.Text, modern)
+		for i := 0; i+n <= len(words); i++ {
+			counts[strings.Join(words[i:i+n], " ")]++
+		}
+	}
+	var grams []NGram
+	for text, count := range counts {
+		grams = append(grams, NGram{N: n, Text: text, Count: count})
+	}
+	sort.Slice(grams, func(i, j int) bool {
+		if grams[i].Count != grams[j].Count {
+			return grams[i].Count > grams[j].Count
+		}
+		return grams[i].Text < grams[j].Text
+	})
+	if len(grams) > top {
+		grams = grams[:top]
+	}
+	return grams
+}
+
+func writeStatsJSON(file string, v interface{}) {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	if err := ioutil.WriteFile(file, bytes, 0777); err != nil {
+		panic(err)
+	}
+	fmt.Println("wrote file: ", file)
+}
+
+func osisRefs(ids []VerseID) []string {
+	var refs []string
+	for _, id := range ids {
+		refs = append(refs, id.OSIS())
+	}
+	return refs
+}
+
+// writeStats writes the concordance, the vocabulary per book, the hapax
+// legomena and the top n-grams as json or csv.
+func writeStats(dir, format string, maxN, top int, modern bool) {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		panic(err)
+	}
+	c := buildConcordance(modern)
+	words := c.sortedWords()
+	hapax := c.hapaxLegomena()
+	var grams []NGram
+	for n := 2; n <= maxN; n++ {
+		grams = append(grams, topNGrams(n, top, modern)...)
+	}
+
+	if format == "json" {
+		writeStatsJSON(filepath.Join(dir, "concordance.json"), words)
+		writeStatsJSON(filepath.Join(dir, "books.json"), c.Books)
+		writeStatsJSON(filepath.Join(dir, "hapax.json"), hapax)
+		writeStatsJSON(filepath.Join(dir, "ngrams.json"), grams)
+	} else {
+		var rows [][]string
+		for _, w := range words {
+			rows = append(rows, []string{w.Word, strconv.Itoa(w.Count), strconv.Itoa(w.Testaments["OT"]), strconv.Itoa(w.Testaments["NT"]),
+				strconv.Itoa(len(w.Books)), strings.Join(osisRefs(w.Refs), " ")})
+		}
+		writeFlatFile(filepath.Join(dir, "concordance.csv"), ',', []string{"word", "count", "ot", "nt", "books", "refs"}, rows)
+
+		rows = nil
+		for _, b := range c.Books {
+			rows = append(rows, []string{b.Book, strconv.Itoa(b.Words), strconv.Itoa(b.Vocabulary), strconv.Itoa(b.Unique), strconv.Itoa(b.Hapax)})
+		}
+		writeFlatFile(filepath.Join(dir, "books.csv"), ',', []string{"book", "words", "vocabulary", "unique", "hapax"}, rows)
+
+		rows = nil
+		for _, w := range hapax {
+			rows = append(rows, []string{w.Word, w.Refs[0].OSIS()})
+		}
+		writeFlatFile(filepath.Join(dir, "hapax.csv"), ',', []string{"word", "ref"}, rows)
+
+		rows = nil
+		for _, g := range grams {
+			rows = append(rows, []string{strconv.Itoa(g.N), g.Text, strconv.Itoa(g.Count)})
+		}
+		writeFlatFile(filepath.Join(dir, "ngrams.csv"), ',', []string{"n", "ngram", "count"}, rows)
+	}
+
+	total := 0
+	for _, b := range c.Books {
+		total += b.Words
+	}
+	fmt.Printf("%v words, %v distinct, %v hapax legomena\n", total, len(words), len(hapax))
+}
+
+func (s *TextStats) addVerse(v *VerseEnhanced) {
+	s.Verses++
+	s.Words += len(textWords(v.Text))
+	s.Characters += utf8.RuneCountInString(v.Text)
+	s.ReadingMinutes = math.Round(float64(s.Words)/readingWordsPerMinute*10) / 10
+}
+
+// enhancedStats counts the verses, words and characters of every book, chapter and titled section.
+func enhancedStats() *EnhancedStats {
+	stats := &EnhancedStats{WordsPerMinute: readingWordsPerMinute}
+	sections := make(map[VerseID]*flatSection)
+	for _, s := range flatSections() {
+		if s.title != "" {
+			sections[s.start] = s
+		}
+	}
+	for _, book := range enhanced {
+		bs := &BookStats{Book: book.Title}
+		for _, c := range book.Chapters {
+			cs := &ChapterStats{Nb: c.Nb}
+			var section *SectionStats
+			for _, v := range c.Verses {
+				if s, ok := sections[v.ID]; ok {
+					section = &SectionStats{Title: s.title, Range: VerseRange{Start: s.start, End: s.end}}
+					cs.Sections = append(cs.Sections, section)
+				}
+				if section != nil {
+					section.addVerse(v)
+				}
+				cs.addVerse(v)
+				bs.addVerse(v)
+				stats.addVerse(v)
+			}
+			bs.Chapters = append(bs.Chapters, cs)
+		}
+		stats.Books = append(stats.Books, bs)
+	}
+	return stats
+}
+
+// writeEnhancedStats writes the stats sidecar of the enhanced books in dir.
+func writeEnhancedStats(dir string, out io.Writer) {
+	bytes, err := json.Marshal(enhancedStats())
+	if err != nil {
+		panic(err)
+	}
+	file := filepath.Join(dir, enhancedStatsFile)
+	if err := ioutil.WriteFile(file, bytes, 0777); err != nil {
+		panic(err)
+	}
+	fmt.Fprintln(out, "wrote file: ", file)
+}
+
+func statsCommand(args []string) {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	out := flags.String("out", statsPath, "output directory")
+	format := flags.String("format", "json", "json or csv")
+	maxN := flags.Int("n", 3, "longest n-gram")
+	top := flags.Int("top", 100, "number of n-grams kept for each length")
+	modern := flags.Bool("modern", false, "count the archaic forms as modern ones, thee as you")
+	lang := localeFlag(flags)
+	_ = flags.Parse(args)
+	useLocale(*lang)
+	if *format != "json" && *format != "csv" {
+		log.Fatalf("unknown format: %s, expecting json or csv", *format)
+	}
+
+	loadEnhancedData()
+	writeStats(*out, *format, *maxN, *top, *modern)
+}
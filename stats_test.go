@@ -0,0 +1,143 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// statsBooks are a few verses of both testaments with a repeated phrase.
+func statsBooks() []*BookEnhanced {
+	return []*BookEnhanced{
+		{Title: "Genesis", Chapters: []*ChapterEnhanced{{Nb: 1, Verses: []*VerseEnhanced{
+			roundTripVerse("Genesis", 1, 1, "The Creation", "In the beginning God created the heaven and the earth."),
+			roundTripVerse("Genesis", 1, 2, "", "And the earth was without form, and void."),
+		}}, {Nb: 2, Verses: []*VerseEnhanced{
+			roundTripVerse("Genesis", 2, 1, "", "Thus the heavens and the earth were finished."),
+		}}}},
+		{Title: "John", Chapters: []*ChapterEnhanced{{Nb: 1, Verses: []*VerseEnhanced{
+			roundTripVerse("John", 1, 1, "The Word", "In the beginning was the Word, and the Word was with God."),
+		}}}},
+	}
+}
+
+func TestTextWords(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"In the beginning God created", []string{"in", "the", "beginning", "god", "created"}},
+		{"the LORD’s house", []string{"the", "lord's", "house"}},
+		{"the LORD's house", []string{"the", "lord's", "house"}},
+		{"‘Go,’ said he; ’tis well-pleasing.", []string{"go", "said", "he", "tis", "well-pleasing"}},
+		{"Moses’ song - 144,000", []string{"moses", "song", "144", "000"}},
+		{"", nil},
+	}
+	for _, test := range tests {
+		if got := textWords(test.text); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("textWords(%q) = %q, expecting %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestBuildConcordance(t *testing.T) {
+	useBooks(t, statsBooks())
+	c := buildConcordance(false)
+	tests := []struct {
+		word      string
+		count     int
+		ot, nt    int
+		firstRef  VerseID
+		bookCount int
+	}{
+		{"the", 9, 6, 3, newVerseID(1, 1, 1), 2},
+		{"god", 2, 1, 1, newVerseID(1, 1, 1), 2},
+		{"earth", 3, 3, 0, newVerseID(1, 1, 1), 1},
+		{"word", 2, 0, 2, newVerseID(43, 1, 1), 1},
+		{"void", 1, 1, 0, newVerseID(1, 1, 2), 1},
+	}
+	for _, test := range tests {
+		entry, ok := c.Words[test.word]
+		if !ok {
+			t.Errorf("%s isn't in the concordance", test.word)
+			continue
+		}
+		if entry.Count != test.count || entry.Testaments["OT"] != test.ot || entry.Testaments["NT"] != test.nt ||
+			entry.Refs[0] != test.firstRef || len(entry.Books) != test.bookCount {
+			t.Errorf("%s counted %d times, %d in the OT, %d in the NT, first in %s, in %d books",
+				test.word, entry.Count, entry.Testaments["OT"], entry.Testaments["NT"], entry.Refs[0], len(entry.Books))
+		}
+	}
+	if len(c.Words["the"].Refs) != c.Words["the"].Count {
+		t.Error("a verse isn't listed once per occurrence")
+	}
+
+	want := []BookVocabulary{
+		{Book: "Genesis", Words: 26, Vocabulary: 16, Unique: 10, Hapax: 9},
+		{Book: "John", Words: 12, Vocabulary: 8, Unique: 2, Hapax: 1},
+	}
+	for i, b := range c.Books {
+		if *b != want[i] {
+			t.Errorf("book vocabulary %+v, expecting %+v", *b, want[i])
+		}
+	}
+}
+
+func TestHapaxLegomena(t *testing.T) {
+	useBooks(t, statsBooks())
+	var words []string
+	for _, entry := range buildConcordance(false).hapaxLegomena() {
+		words = append(words, entry.Word)
+	}
+	want := []string{"created", "finished", "form", "heaven", "heavens", "thus", "void", "were", "with", "without"}
+	if !reflect.DeepEqual(words, want) {
+		t.Errorf("hapax legomena %v, expecting %v", words, want)
+	}
+}
+
+func TestTopNGrams(t *testing.T) {
+	useBooks(t, statsBooks())
+	tests := []struct {
+		n, top int
+		want   []NGram
+	}{
+		{2, 3, []NGram{{2, "and the", 4}, {2, "the earth", 3}, {2, "in the", 2}}},
+		{3, 2, []NGram{{3, "and the earth", 3}, {3, "in the beginning", 2}}},
+	}
+	for _, test := range tests {
+		if got := topNGrams(test.n, test.top, false); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("topNGrams(%d, %d) = %v, expecting %v", test.n, test.top, got, test.want)
+		}
+	}
+}
+
+func TestEnhancedStats(t *testing.T) {
+	useBooks(t, statsBooks())
+	stats := enhancedStats()
+	if stats.WordsPerMinute != readingWordsPerMinute || stats.TextStats != (TextStats{Verses: 4, Words: 38, Characters: 197, ReadingMinutes: 0.2}) {
+		t.Errorf("whole text stats %+v", stats.TextStats)
+	}
+	tests := []struct {
+		got  TextStats
+		want TextStats
+	}{
+		{stats.Books[0].TextStats, TextStats{Verses: 3, Words: 26, Characters: 140, ReadingMinutes: 0.1}},
+		{stats.Books[0].Chapters[0].TextStats, TextStats{Verses: 2, Words: 18, Characters: 95, ReadingMinutes: 0.1}},
+		{stats.Books[0].Chapters[1].TextStats, TextStats{Verses: 1, Words: 8, Characters: 45, ReadingMinutes: 0}},
+		{stats.Books[1].TextStats, TextStats{Verses: 1, Words: 12, Characters: 57, ReadingMinutes: 0.1}},
+	}
+	for i, test := range tests {
+		if test.got != test.want {
+			t.Errorf("stats %d are %+v, expecting %+v", i, test.got, test.want)
+		}
+	}
+
+	// a section runs from its title to the end of the chapter
+	sections := stats.Books[0].Chapters[0].Sections
+	if len(sections) != 1 || sections[0].Title != "The Creation" || sections[0].Range != (VerseRange{Start: newVerseID(1, 1, 1), End: newVerseID(1, 1, 2)}) ||
+		sections[0].TextStats != stats.Books[0].Chapters[0].TextStats {
+		t.Errorf("Genesis 1 sections %+v", sections)
+	}
+	if sections := stats.Books[0].Chapters[1].Sections; len(sections) != 0 {
+		t.Errorf("Genesis 2 has no title but got sections %+v", sections)
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Token is a piece of verse text with the Strong's numbers it translates.
+// Joining the Text of every token of a verse gives back the verse text.
+type Token struct {
+	Text    string   `json:"text"`
+	Strongs []string `json:"strongs,omitempty"`
+}
+
+// StrongsHit is one occurrence of a Strong's number, Token being the index in the verse tokens.
+type StrongsHit struct {
+	ID    VerseID
+	Token int
+	Text  string
+}
+
+var strongsPath = "./json/kjv-strongs.txt"
+var strongsTags = regexp.MustCompile(`\{([^}]*)\}`)
+var strongsNumber = regexp.MustCompile(`^([HG])0*(\d+)([a-z]?)$`)
+var strongsTokens = make(map[VerseID][]Token)
+var strongsIndex = make(map[string][]StrongsHit)
+
+// loadStrongs reads a KJV text tagged with Strong's numbers in the KJV+ brace
+// style, one verse per line with the reference and text separated by a tab:
+// "Gen.1.1	In the beginning{H7225} God{H430} created{H1254}{(H8804)}{H853} ..."
+// Each tag applies to the words since the previous tag, morphology codes in
+// parentheses are ignored. The numbers loaded before are replaced.
+func loadStrongs(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("failed to open strongs file %s: %w", file, err)
+	}
+	defer f.Close()
+
+	fmt.Println("reading strongs numbers...")
+	strongsTokens = make(map[VerseID][]Token)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNb := 0
+	for scanner.Scan() {
+		lineNb++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		cols := strings.SplitN(line, "\t", 2)
+		if len(cols) != 2 {
+			logError(fmt.Errorf("%s:%d: expecting a reference and a text", file, lineNb))
+			continue
+		}
+		r, err := parseReference(cols[0])
+		if err != nil {
+			logError(fmt.Errorf("%s:%d: %w", file, lineNb, err))
+			continue
+		}
+		strongsTokens[r.Start] = tokenizeStrongs(cols[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read strongs file %s: %w", file, err)
+	}
+
+	strongsIndex = make(map[string][]StrongsHit)
+	for id, tokens := range strongsTokens {
+		for i, t := range tokens {
+			for _, nb := range t.Strongs {
+				strongsIndex[nb] = append(strongsIndex[nb], StrongsHit{ID: id, Token: i, Text: strings.TrimSpace(t.Text)})
+			}
+		}
+	}
+	for nb := range strongsIndex {
+		hits := strongsIndex[nb]
+		sort.Slice(hits, func(i, j int) bool {
+			if hits[i].ID != hits[j].ID {
+				return hits[i].ID < hits[j].ID
+			}
+			return hits[i].Token < hits[j].Token
+		})
+	}
+	fmt.Printf("read strongs numbers for %v verses\n", len(strongsTokens))
+	return nil
+}
+
+func tokenizeStrongs(text string) []Token {
+	var tokens []Token
+	last := 0
+	for _, loc := range strongsTags.FindAllStringSubmatchIndex(text, -1) {
+		segment := text[last:loc[0]]
+		last = loc[1]
+		nb := normalizeStrongs(text[loc[2]:loc[3]])
+		if segment == "" && len(tokens) > 0 {
+			// several tags in a row belong to the same words
+			if nb != "" {
+				tokens[len(tokens)-1].Strongs = append(tokens[len(tokens)-1].Strongs, nb)
+			}
+			continue
+		}
+		t := Token{Text: segment}
+		if nb != "" {
+			t.Strongs = []string{nb}
+		}
+		tokens = append(tokens, t)
+	}
+	if rest := text[last:]; rest != "" {
+		tokens = append(tokens, Token{Text: rest})
+	}
+	return tokens
+}
+
+// normalizeStrongs turns "H07225" or "h7225" into "H7225", returning "" for morphology codes.
+func normalizeStrongs(nb string) string {
+	nb = strings.TrimSpace(nb)
+	if nb == "" {
+		return ""
+	}
+	m := strongsNumber.FindStringSubmatch(strings.ToUpper(nb[:1]) + nb[1:])
+	if m == nil {
+		return ""
+	}
+	return m[1] + m[2] + m[3]
+}
+
+func tokensText(tokens []Token) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteString(t.Text)
+	}
+	return b.String()
+}
+
+// applyStrongs attaches the tokens to the verses whose text they reproduce,
+// logging the verses where the tagged text differs. The tokens get the default
+// typography of the verse text, "name's" matching "name’s".
+func applyStrongs() {
+	applied, mismatched := 0, 0
+	for id, tokens := range strongsTokens {
+		v, ok := enhancedVerses[id]
+		if !ok {
+			logError(fmt.Errorf("strongs tokens for unknown verse: %s", id))
+			continue
+		}
+		tokens = normalizeTokens(tokens, defaultTypography)
+		if strings.Join(strings.Fields(tokensText(tokens)), " ") != strings.Join(strings.Fields(normalizeText(v.Text)), " ") {
+			logError(fmt.Errorf("strongs text doesn't match verse %s: %s, expecting: %s", id, tokensText(tokens), v.Text))
+			mismatched++
+			continue
+		}
+		v.Tokens = tokens
+		applied++
+	}
+	fmt.Printf("applied strongs numbers to %v verses, %v didn't match\n", applied, mismatched)
+}
+
+func lookupStrongs(nb string) []StrongsHit {
+	return strongsIndex[normalizeStrongs(nb)]
+}
+
+func strongsCommand(args []string) {
+	fs := flag.NewFlagSet("strongs", flag.ExitOnError)
+	file := fs.String("file", strongsPath, "KJV text tagged with strongs numbers")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: strongs [-file path] <number>")
+		os.Exit(2)
+	}
+	if normalizeStrongs(fs.Arg(0)) == "" {
+		log.Fatalf("invalid strongs number: %s", fs.Arg(0))
+	}
+
+	if err := loadStrongs(*file); err != nil {
+		log.Fatal(err)
+	}
+	hits := lookupStrongs(fs.Arg(0))
+	for _, hit := range hits {
+		fmt.Printf("%s: %s\n", hit.ID, hit.Text)
+	}
+	fmt.Printf("%v occurrences\n", len(hits))
+}
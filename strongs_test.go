@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTokenizeStrongs(t *testing.T) {
+	tests := []struct {
+		text   string
+		tokens []Token
+	}{
+		{"", nil},
+		{"Jesus wept.", []Token{{Text: "Jesus wept."}}},
+		{"In the beginning{H7225} God{H430}.", []Token{
+			{Text: "In the beginning", Strongs: []string{"H7225"}},
+			{Text: " God", Strongs: []string{"H430"}},
+			{Text: "."},
+		}},
+		{"created{H1254}{(H8804)}{H853} the heaven{H08064}", []Token{
+			{Text: "created", Strongs: []string{"H1254", "H853"}},
+			{Text: " the heaven", Strongs: []string{"H8064"}},
+		}},
+		{"was{(H8804)} without form{h8414}", []Token{
+			{Text: "was"},
+			{Text: " without form", Strongs: []string{"H8414"}},
+		}},
+		{"the Word{G3056}, and", []Token{
+			{Text: "the Word", Strongs: []string{"G3056"}},
+			{Text: ", and"},
+		}},
+	}
+	for _, test := range tests {
+		if got := tokenizeStrongs(test.text); !reflect.DeepEqual(got, test.tokens) {
+			t.Errorf("tokenizeStrongs(%q) = %v, expecting %v", test.text, got, test.tokens)
+		}
+	}
+}
+
+func TestNormalizeStrongs(t *testing.T) {
+	tests := []struct {
+		nb, want string
+	}{
+		{"H7225", "H7225"},
+		{"H07225", "H7225"},
+		{"h7225", "H7225"},
+		{" G25 ", "G25"},
+		{"G3056a", "G3056a"},
+		{"(H8804)", ""},
+		{"H", ""},
+		{"X12", ""},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := normalizeStrongs(test.nb); got != test.want {
+			t.Errorf("normalizeStrongs(%q) = %q, expecting %q", test.nb, got, test.want)
+		}
+	}
+}
+
+func TestApplyStrongsTypography(t *testing.T) {
+	v := roundTripVerse("Psalms", 23, 3, "", "He restoreth my soul: he leadeth me in the paths of righteousness for his name’s sake.")
+	useBooks(t, []*BookEnhanced{{Title: "Psalms", Chapters: []*ChapterEnhanced{{Nb: 23, Verses: []*VerseEnhanced{v}}}}})
+	saved := strongsTokens
+	defer func() { strongsTokens = saved }()
+	strongsTokens = map[VerseID][]Token{v.ID: tokenizeStrongs("He restoreth{H7725} my soul{H5315}: he leadeth{H5148} me in the paths{H4570} " +
+		"of righteousness{H6664} for his name's{H8034} sake{H4616}.")}
+
+	applyStrongs()
+	if v.Tokens == nil {
+		t.Fatal("tokens with a straight apostrophe weren't applied to the verse")
+	}
+	if got := tokensText(v.Tokens); got != v.Text {
+		t.Errorf("tokens give %q, expecting %q", got, v.Text)
+	}
+}
+
+func TestNormalizeTokens(t *testing.T) {
+	tokens := []Token{{Text: "'Tis"}, {Text: " his name's", Strongs: []string{"H8034"}}, {Text: "  sake "}}
+	got := normalizeTokens(tokens, defaultTypography)
+	want := []string{"’Tis", " his name’s", " sake"}
+	for i, token := range got {
+		if token.Text != want[i] {
+			t.Errorf("token %d is %q, expecting %q", i, token.Text, want[i])
+		}
+	}
+	if len(got[1].Strongs) != 1 {
+		t.Errorf("token 1 lost its strongs numbers: %v", got[1].Strongs)
+	}
+}
+
+func TestLoadStrongsReplaces(t *testing.T) {
+	savedTokens, savedIndex := strongsTokens, strongsIndex
+	defer func() { strongsTokens, strongsIndex = savedTokens, savedIndex }()
+	dir := t.TempDir()
+	for i, line := range []string{
+		"Gen.1.1\tIn the beginning{H7225} God{H430} created{H1254}{(H8804)} the heaven{H8064}.",
+		"Gen.1.2\tAnd the earth{H776} was{H1961}{(H8804)} without form{H8414}.",
+	} {
+		file := filepath.Join(dir, fmt.Sprintf("strongs%d.txt", i))
+		if err := ioutil.WriteFile(file, []byte(line+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := loadStrongs(file); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(strongsTokens) != 1 {
+		t.Errorf("%d verses tagged after loading a second file, expecting 1", len(strongsTokens))
+	}
+	if hits := lookupStrongs("H430"); len(hits) != 0 {
+		t.Errorf("H430 of the first file still has %d occurrences", len(hits))
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+var summariesPaths = []string{"./json/summaries.json", "./json/summaries.md"}
+
+// loadSummaries reads book introductions and chapter summaries keyed by
+// reference, "Genesis" for the introduction of the book and "Genesis 1" for
+// the summary of the chapter. Json files hold an object of reference to text,
+// markdown files use a heading with the reference followed by the text.
+func loadSummaries(file string) (map[string]string, error) {
+	bytes, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read summaries %s: %w", file, err)
+	}
+	if filepath.Ext(file) == ".json" {
+		entries := make(map[string]string)
+		if err := json.Unmarshal(bytes, &entries); err != nil {
+			return nil, fmt.Errorf("failed to read summaries %s: %w", file, err)
+		}
+		return entries, nil
+	}
+
+	entries := make(map[string]string)
+	key := ""
+	var body []string
+	save := func() {
+		if key != "" {
+			entries[key] = strings.TrimSpace(strings.Join(body, "\n"))
+		}
+	}
+	for _, line := range strings.Split(string(bytes), "\n") {
+		if strings.HasPrefix(line, "#") {
+			save()
+			key = strings.TrimSpace(strings.TrimLeft(line, "#"))
+			body = nil
+			continue
+		}
+		body = append(body, strings.TrimRight(line, " \r"))
+	}
+	save()
+	return entries, nil
+}
+
+// applySummaries sets the introductions and summaries on the enhanced books,
+// logging the entries that don't point to an existing book or chapter.
+func applySummaries(entries map[string]string) {
+	intros, summaries := 0, 0
+	for ref, text := range entries {
+		if info := bookInfo(ref); info != nil {
+			book := getEnhancedBook(info.Name)
+			if book == nil {
+				logError(fmt.Errorf("introduction for a book that isn't loaded: %s", ref))
+				continue
+			}
+			book.Introduction = text
+			intros++
+			continue
+		}
+
+		r, err := parseReference(ref)
+		if err != nil {
+			logError(fmt.Errorf("invalid summary reference: %w", err))
+			continue
+		}
+		info := r.Start.BookInfo()
+		if r.Start.Chapter() != r.End.Chapter() || r.Start.Verse() != 1 || r.End.Verse() != info.VerseCount(r.End.Chapter()) {
+			logError(fmt.Errorf("summary must refer to a whole book or chapter: %s", ref))
+			continue
+		}
+		chap := getEnhancedChapter(info.Name, fmt.Sprintf("%d", r.Start.Chapter()))
+		if chap == nil {
+			logError(fmt.Errorf("summary for a chapter that isn't loaded: %s", ref))
+			continue
+		}
+		chap.Summary = text
+		summaries++
+	}
+	fmt.Printf("applied %v introductions and %v chapter summaries!\n", intros, summaries)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLoadSummaries(t *testing.T) {
+	tests := []struct {
+		name, data string
+		entries    map[string]string
+	}{
+		{
+			"summaries.json",
+			`{"Ruth": "The story of Ruth the Moabitess.", "Ruth 1": "Naomi goes back to Bethlehem."}`,
+			map[string]string{"Ruth": "The story of Ruth the Moabitess.", "Ruth 1": "Naomi goes back to Bethlehem."},
+		},
+		{
+			"summaries.md",
+			"# Ruth\r\nThe story of Ruth  \r\nthe Moabitess.\r\n\r\n## Ruth 1\n\nNaomi goes back to Bethlehem.\n\n## Ruth 2\n",
+			map[string]string{"Ruth": "The story of Ruth\nthe Moabitess.", "Ruth 1": "Naomi goes back to Bethlehem.", "Ruth 2": ""},
+		},
+		{"empty.md", "no heading yet\n", map[string]string{}},
+	}
+	for _, test := range tests {
+		entries, err := loadSummaries(writeTestFile(t, test.name, test.data))
+		if err != nil {
+			t.Errorf("loadSummaries(%s): %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(entries, test.entries) {
+			t.Errorf("loadSummaries(%s) = %q, expecting %q", test.name, entries, test.entries)
+		}
+	}
+	if _, err := loadSummaries(writeTestFile(t, "broken.json", `{"Ruth": 1}`)); err == nil {
+		t.Error("loadSummaries(broken.json) read a number as a summary")
+	}
+}
+
+func TestApplySummaries(t *testing.T) {
+	chap1 := &ChapterEnhanced{Nb: 1, Verses: []*VerseEnhanced{roundTripVerse("Ruth", 1, 1, "", "Now it came to pass in the days when the judges ruled.")}}
+	chap2 := &ChapterEnhanced{Nb: 2, Verses: []*VerseEnhanced{roundTripVerse("Ruth", 2, 1, "", "And Naomi had a kinsman of her husband’s.")}}
+	ruth := &BookEnhanced{Title: "Ruth", Chapters: []*ChapterEnhanced{chap1, chap2}}
+	useBooks(t, []*BookEnhanced{ruth})
+
+	applySummaries(map[string]string{
+		"Ruth":   "The story of Ruth the Moabitess.",
+		"Ruth.1": "Naomi goes back to Bethlehem.",
+		"Ruth 2": "Ruth gleans in the field of Boaz.",
+	})
+	tests := []struct {
+		got, want string
+	}{
+		{ruth.Introduction, "The story of Ruth the Moabitess."},
+		{chap1.Summary, "Naomi goes back to Bethlehem."},
+		{chap2.Summary, "Ruth gleans in the field of Boaz."},
+	}
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("summary is %q, expecting %q", test.got, test.want)
+		}
+	}
+}
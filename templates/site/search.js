@@ -0,0 +1,31 @@
+(function () {
+  var params = new URLSearchParams(location.search);
+  var query = (params.get("q") || "").trim().toLowerCase();
+  var status = document.getElementById("search-status");
+  var results = document.getElementById("search-results");
+  var root = document.currentScript.src.replace(/search\.js$/, "");
+  if (!query) {
+    status.textContent = "Type some words to search.";
+    return;
+  }
+  status.textContent = "Searching...";
+  fetch(root + "search-index.json")
+    .then(function (res) { return res.json(); })
+    .then(function (index) {
+      var words = query.split(/\s+/);
+      var found = index.filter(function (entry) {
+        var text = (entry.t + " " + (entry.s || "")).toLowerCase();
+        return words.every(function (w) { return text.indexOf(w) !== -1; });
+      });
+      status.textContent = found.length + " results for “" + query + "”";
+      found.slice(0, 500).forEach(function (entry) {
+        var li = document.createElement("li");
+        var a = document.createElement("a");
+        a.href = root + entry.u;
+        a.textContent = entry.r;
+        li.appendChild(a);
+        li.appendChild(document.createTextNode(" " + entry.t));
+        results.appendChild(li);
+      });
+    });
+})();
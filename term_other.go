@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package main
+
+func terminalWidth() int {
+	return 0
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var titleLintReportPath = "./titles-lint.json"
+
+const maxTitleLength = 80
+
+// minorWords stay lower case in a title, unless first or last, the
+// prepositions of any length included as in "Laws about Divorce".
+var minorWords = makeSet(strings.Fields(`a an and as at but by for from in into nor of on or over the to unto upon up via
+	with about above across after against along among around before behind below beneath beside between beyond
+	during except through throughout till toward towards under until within without`))
+
+var htmlEntity = regexp.MustCompile(`&(?:#\d+|#x[0-9a-fA-F]+|[a-zA-Z]+);`)
+
+// TitleIssue is one problem found in a section title, Fix being the fixed
+// title, empty when the title should be removed or can't be fixed.
+type TitleIssue struct {
+	Ref     string `json:"ref"`
+	Source  string `json:"source"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	Title   string `json:"title"`
+	Fix     string `json:"fix,omitempty"`
+	Fixable bool   `json:"fixable"`
+}
+
+// titleCase capitalizes every word but the minor ones, leaving the rest of
+// each word alone so "LORD" and "One-Tenth" are kept.
+func titleCase(title string) string {
+	words := strings.Split(title, " ")
+	for i, w := range words {
+		r, size := utf8.DecodeRuneInString(w)
+		if r == utf8.RuneError {
+			continue
+		}
+		lower := strings.ToLower(strings.Trim(w, ",;:"))
+		if minorWords[lower] && i > 0 && i < len(words)-1 && !strings.HasSuffix(words[i-1], ":") {
+			words[i] = strings.ToLower(w)
+		} else {
+			words[i] = string(unicode.ToUpper(r)) + w[size:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// lintTitle checks one title, prev being the title of the previous section of
+// the chapter, if any, and opts the typography expected.
+func lintTitle(ref, source, title, prev string, opts TypographyOptions) []TitleIssue {
+	var issues []TitleIssue
+	add := func(rule, message, fix string, fixable bool) {
+		issues = append(issues, TitleIssue{Ref: ref, Source: source, Rule: rule, Message: message, Title: title, Fix: fix, Fixable: fixable})
+	}
+
+	if htmlEntity.MatchString(title) || xmlTags.MatchString(title) {
+		add("entity", "html entity or markup left in the title", strings.TrimSpace(html.UnescapeString(xmlTags.ReplaceAllString(title, ""))), true)
+	}
+	if spaced(title) != title {
+		add("spacing", "extra whitespace", spaced(title), true)
+	}
+	if trimmed := strings.TrimRight(title, ".,;: "); trimmed != strings.TrimRight(title, " ") {
+		add("punctuation", "trailing punctuation", trimmed, true)
+	}
+	if typed, _ := normalizeTypography(spaced(title), opts); typed != spaced(title) {
+		add("typography", "quotes, dashes or characters other than the expected typography", typed, true)
+	}
+	if cased := titleCase(title); cased != title {
+		add("casing", "expecting title case", cased, true)
+	}
+	if n := utf8.RuneCountInString(title); n > maxTitleLength {
+		add("length", fmt.Sprintf("%d characters, expecting at most %d", n, maxTitleLength), "", false)
+	} else if n < 3 {
+		add("length", fmt.Sprintf("%d characters, too short to be a title", n), "", false)
+	}
+	if prev != "" && strings.EqualFold(strings.TrimSpace(prev), strings.TrimSpace(title)) {
+		add("duplicate", "same title as the previous section", "", true)
+	}
+	return issues
+}
+
+// fixTitle applies every fix of a title, an empty result removing it.
+func fixTitle(title, prev string, opts TypographyOptions) string {
+	if prev != "" && strings.EqualFold(strings.TrimSpace(prev), strings.TrimSpace(title)) {
+		return ""
+	}
+	title = spaced(html.UnescapeString(xmlTags.ReplaceAllString(title, "")))
+	title = strings.TrimRight(title, ".,;: ")
+	title, _ = normalizeTypography(title, opts)
+	return titleCase(title)
+}
+
+func spaced(title string) string {
+	return strings.Join(strings.Fields(title), " ")
+}
+
+// lintEnhancedTitles checks the titles of the enhanced verses, fixing them when fix is set.
+func lintEnhancedTitles(fix bool, opts TypographyOptions) []TitleIssue {
+	var issues []TitleIssue
+	for _, book := range enhanced {
+		for _, c := range book.Chapters {
+			prev := ""
+			for _, v := range c.Verses {
+				if v.Title == "" {
+					continue
+				}
+				issues = append(issues, lintTitle(v.ID.OSIS(), "enhanced", v.Title, prev, opts)...)
+				title := v.Title
+				if fix {
+					v.Title = fixTitle(v.Title, prev, opts)
+				}
+				prev = title
+			}
+		}
+	}
+	return issues
+}
+
+// lintExceptionTitles checks the titles hard coded in verseTitlesExceptions,
+// which are fixed in the source.
+func lintExceptionTitles(opts TypographyOptions) []TitleIssue {
+	var issues []TitleIssue
+	for _, info := range catalogue {
+		chapters := verseTitlesExceptions[info.Name]
+		var chapterNbs []int
+		for c := range chapters {
+			nb, _ := strconv.Atoi(c)
+			chapterNbs = append(chapterNbs, nb)
+		}
+		sort.Ints(chapterNbs)
+		for _, c := range chapterNbs {
+			titles := chapters[strconv.Itoa(c)]
+			var verseNbs []int
+			for v := range titles {
+				nb, _ := strconv.Atoi(v)
+				verseNbs = append(verseNbs, nb)
+			}
+			sort.Ints(verseNbs)
+			prev := ""
+			for _, v := range verseNbs {
+				title := titles[strconv.Itoa(v)]
+				for _, issue := range lintTitle(newVerseID(info.Nb, c, v).OSIS(), "exceptions", title, prev, opts) {
+					issue.Fixable = false
+					issues = append(issues, issue)
+				}
+				prev = title
+			}
+		}
+	}
+	return issues
+}
+
+func lintTitlesCommand(args []string) {
+	flags := flag.NewFlagSet("lint-titles", flag.ExitOnError)
+	fix := flags.Bool("fix", false, "fix the enhanced titles and rewrite the books")
+	report := flags.String("report", titleLintReportPath, "json report of the issues, - for stdout")
+	typography := typographyFlags(flags)
+	_ = flags.Parse(args)
+	opts := typography()
+
+	loadEnhancedDataQuiet()
+	issues := append(lintEnhancedTitles(*fix, opts), lintExceptionTitles(opts)...)
+	if issues == nil {
+		issues = []TitleIssue{}
+	}
+	remaining := 0
+	for _, issue := range issues {
+		if !*fix || !issue.Fixable {
+			remaining++
+			fmt.Fprintf(os.Stderr, "%s (%s): %s: %s: %q\n", issue.Ref, issue.Source, issue.Rule, issue.Message, issue.Title)
+		}
+	}
+	if *fix && len(issues) > remaining {
+		writeEnhancedBooks(os.Stderr)
+	}
+
+	bytes, err := json.MarshalIndent(issues, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	if *report == "-" {
+		fmt.Println(string(bytes))
+	} else {
+		if err := ioutil.WriteFile(*report, bytes, 0777); err != nil {
+			panic(err)
+		}
+		fmt.Fprintln(os.Stderr, "wrote file: ", *report)
+	}
+	fmt.Fprintf(os.Stderr, "%v title issues, %v left to fix\n", len(issues), remaining)
+	if remaining > 0 {
+		os.Exit(1)
+	}
+}
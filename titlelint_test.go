@@ -0,0 +1,100 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestTitleCase(t *testing.T) {
+	tests := []struct {
+		title, want string
+	}{
+		{"the fall of man", "The Fall of Man"},
+		{"laws about divorce", "Laws about Divorce"},
+		{"a song of praise to the LORD", "A Song of Praise to the LORD"},
+		{"the LORD is my shepherd", "The LORD Is My Shepherd"},
+		{"what to do with", "What to Do With"},
+		{"Jesus Teaches: the Beatitudes", "Jesus Teaches: The Beatitudes"},
+		{"one-tenth for the priests", "One-tenth for the Priests"},
+		{"Israel’s past, present, and future", "Israel’s Past, Present, and Future"},
+	}
+	for _, test := range tests {
+		if got := titleCase(test.title); got != test.want {
+			t.Errorf("titleCase(%q) = %q, expecting %q", test.title, got, test.want)
+		}
+	}
+}
+
+func TestLintTitle(t *testing.T) {
+	straight := TypographyOptions{Quotes: "straight", Whitespace: true}
+	tests := []struct {
+		title, prev string
+		opts        TypographyOptions
+		rules       []string
+		fix         string
+	}{
+		{"The Creation", "", defaultTypography, nil, "The Creation"},
+		{"The LORD Appears to Abram", "", defaultTypography, nil, "The LORD Appears to Abram"},
+		{"Living in the truth", "", defaultTypography, []string{"casing"}, "Living in the Truth"},
+		{"God's Patience", "", defaultTypography, []string{"typography"}, "God’s Patience"},
+		{"God’s Patience", "", straight, []string{"typography"}, "God's Patience"},
+		{"God's Patience", "", straight, nil, "God's Patience"},
+		{"Moses &amp; Aaron", "", defaultTypography, []string{"entity"}, "Moses & Aaron"},
+		{"The <i>Ten</i> Commandments", "", defaultTypography, []string{"entity"}, "The Ten Commandments"},
+		{"The  Ten Commandments.", "", defaultTypography, []string{"spacing", "punctuation"}, "The Ten Commandments"},
+		{"The Ten Commandments", "the ten commandments", defaultTypography, []string{"duplicate"}, ""},
+		{"Of", "", defaultTypography, []string{"length"}, "Of"},
+	}
+	for _, test := range tests {
+		issues := lintTitle("Gen.1.1", "enhanced", test.title, test.prev, test.opts)
+		var rules []string
+		for _, issue := range issues {
+			rules = append(rules, issue.Rule)
+		}
+		if len(rules) != len(test.rules) {
+			t.Errorf("lintTitle(%q) found %v, expecting %v", test.title, rules, test.rules)
+		} else {
+			for i := range rules {
+				if rules[i] != test.rules[i] {
+					t.Errorf("lintTitle(%q) found %v, expecting %v", test.title, rules, test.rules)
+					break
+				}
+			}
+		}
+		if got := fixTitle(test.title, test.prev, test.opts); got != test.fix {
+			t.Errorf("fixTitle(%q) = %q, expecting %q", test.title, got, test.fix)
+		}
+	}
+}
+
+func TestExceptionTitles(t *testing.T) {
+	for _, issue := range lintExceptionTitles(defaultTypography) {
+		t.Errorf("%s: %s: %q", issue.Ref, issue.Message, issue.Title)
+	}
+
+	// the exception titles as they were before the linter
+	exceptions := make(map[string]bool)
+	for _, chapters := range verseTitlesExceptions {
+		for _, verses := range chapters {
+			for _, title := range verses {
+				exceptions[title] = true
+			}
+		}
+	}
+	for _, title := range []string{
+		"Hannah, Having Given Birth to Samuel, Stays at Home till he is Weaned",
+		"Living in the truth",
+		"God's Power for Godly Lives",
+		"Be Ready for Christ's Return",
+		"Moses Tells of Sending the Spies and the People's Rebellion",
+		"Dealing with a Family Member's Idolatry",
+		"Israel's Past, Present, and Future",
+		"Moses' Song Which Sets Forth God's Mercy and Vengeance",
+	} {
+		if len(lintTitle("Gen.1.1", "exceptions", title, "", defaultTypography)) == 0 {
+			t.Errorf("lintTitle(%q) found no issue", title)
+		}
+		if fixed := fixTitle(title, "", defaultTypography); !exceptions[fixed] {
+			t.Errorf("fixTitle(%q) = %q, which isn't an exception title", title, fixed)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var translationsPath = "./json/translations"
+
+const defaultTranslation = "kjv"
+
+// Translation is one bible loaded in the registry, Verses indexes the verses of Books.
+type Translation struct {
+	Code   string
+	Name   string
+	Books  []*BookEnhanced
+	Verses map[VerseID]*VerseEnhanced
+}
+
+// translationInfo is the optional translation.json of a translation directory.
+type translationInfo struct {
+	Name string `json:"name"`
+}
+
+// ParallelVerse is one reference across the translations, Unmapped lists the
+// translations which don't have the verse in their versification.
+type ParallelVerse struct {
+	ID       VerseID                   `json:"id"`
+	Verses   map[string]*VerseEnhanced `json:"verses"`
+	Unmapped []string                  `json:"unmapped,omitempty"`
+}
+
+var translations = make(map[string]*Translation)
+
+func registerTranslation(code, name string, books []*BookEnhanced) *Translation {
+	t := &Translation{Code: strings.ToLower(code), Name: name, Books: books, Verses: make(map[VerseID]*VerseEnhanced)}
+	for _, book := range books {
+		info := mustBookInfo(book.Title)
+		for _, c := range book.Chapters {
+			for _, v := range c.Verses {
+				if v.ID == 0 {
+					v.ID = newVerseID(info.Nb, c.Nb, v.Nb)
+					v.OSIS = v.ID.OSIS()
+				}
+				t.Verses[v.ID] = v
+			}
+		}
+	}
+	translations[t.Code] = t
+	return t
+}
+
+func getTranslation(code string) *Translation {
+	return translations[strings.ToLower(code)]
+}
+
+func translationCodes() []string {
+	var codes []string
+	for code := range translations {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// loadTranslation reads the enhanced json of a translation, as written by the
+// import command, the books it doesn't have are skipped.
+func loadTranslation(code, dir string) (*Translation, error) {
+	name := strings.ToUpper(code)
+	if bytes, err := ioutil.ReadFile(filepath.Join(dir, "translation.json")); err == nil {
+		info := translationInfo{}
+		if err := json.Unmarshal(bytes, &info); err != nil {
+			return nil, fmt.Errorf("failed to read %s translation info: %w", code, err)
+		}
+		if info.Name != "" {
+			name = info.Name
+		}
+	}
+	var books []*BookEnhanced
+	for _, info := range catalogue {
+		bytes, err := ioutil.ReadFile(filepath.Join(dir, info.FileName()+".json"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		book := new(BookEnhanced)
+		if err := json.Unmarshal(bytes, book); err != nil {
+			return nil, fmt.Errorf("failed to read %s book %s: %w", code, info.Name, err)
+		}
+		books = append(books, book)
+	}
+	if len(books) == 0 {
+		return nil, fmt.Errorf("no books found for translation %s in %s", code, dir)
+	}
+	return registerTranslation(code, name, books), nil
+}
+
+// loadTranslations registers the enhanced books as the kjv and every
+// translation directory found in dir.
+func loadTranslations(dir string) {
+	registerTranslation(defaultTranslation, "King James Version", enhanced)
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == defaultTranslation {
+			continue
+		}
+		if _, err := loadTranslation(entry.Name(), filepath.Join(dir, entry.Name())); err != nil {
+			logError(err)
+		}
+	}
+}
+
+// parallelVerses looks up the range in each translation. The verses a
+// translation has past the end of the range, in its last chapter, are
+// added too, so the ones missing from the other translations are reported.
+func parallelVerses(r VerseRange, codes []string) ([]*ParallelVerse, error) {
+	var list []*Translation
+	for _, code := range codes {
+		t := getTranslation(code)
+		if t == nil {
+			return nil, fmt.Errorf("unknown translation: %s, expecting one of %v", code, translationCodes())
+		}
+		list = append(list, t)
+	}
+
+	ids := r.IDs()
+	if r.End.Verse() == r.End.BookInfo().VerseCount(r.End.Chapter()) {
+		extra := make(map[VerseID]bool)
+		for _, t := range list {
+			for id := range t.Verses {
+				if id.Book() == r.End.Book() && id.Chapter() == r.End.Chapter() && id > r.End {
+					extra[id] = true
+				}
+			}
+		}
+		var more []VerseID
+		for id := range extra {
+			more = append(more, id)
+		}
+		sort.Slice(more, func(i, j int) bool { return more[i] < more[j] })
+		ids = append(ids, more...)
+	}
+
+	var rows []*ParallelVerse
+	for _, id := range ids {
+		row := &ParallelVerse{ID: id, Verses: make(map[string]*VerseEnhanced)}
+		for _, t := range list {
+			if v, ok := t.Verses[id]; ok {
+				row.Verses[t.Code] = v
+			} else {
+				row.Unmapped = append(row.Unmapped, t.Code)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func parallelCommand(args []string) {
+	fs := flag.NewFlagSet("parallel", flag.ExitOnError)
+	codes := fs.String("t", "", "comma separated translation codes, defaults to all the loaded ones")
+	dir := fs.String("dir", translationsPath, "directory of the imported translations")
+	asJSON := fs.Bool("json", false, "print json")
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: parallel [-t kjv,web] [-dir dir] [-json] <reference>")
+		os.Exit(2)
+	}
+
+	r, err := parseReference(strings.Join(fs.Args(), " "))
+	if err != nil {
+		log.Fatal(err)
+	}
+	loadEnhancedDataQuiet()
+	loadTranslations(*dir)
+	selected := translationCodes()
+	if *codes != "" {
+		selected = strings.Split(*codes, ",")
+	}
+	rows, err := parallelVerses(r, selected)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	if *asJSON {
+		bytes, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		out.Write(append(bytes, '\n'))
+		return
+	}
+	unmapped := 0
+	for _, row := range rows {
+		fmt.Fprintf(out, "%s\n", row.ID)
+		for _, code := range selected {
+			if v, ok := row.Verses[strings.ToLower(code)]; ok {
+				fmt.Fprintf(out, "  %-6s %s\n", strings.ToUpper(code), v.Text)
+			}
+		}
+		if len(row.Unmapped) > 0 {
+			fmt.Fprintf(out, "  unmapped in %s\n", strings.ToUpper(strings.Join(row.Unmapped, ", ")))
+			unmapped++
+		}
+	}
+	if unmapped > 0 {
+		fmt.Fprintf(out, "%v of %v verses are unmapped in some translation\n", unmapped, len(rows))
+	}
+}
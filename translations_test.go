@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// useTranslations makes the registry empty for the duration of the test.
+func useTranslations(t *testing.T) {
+	saved := translations
+	t.Cleanup(func() { translations = saved })
+	translations = make(map[string]*Translation)
+}
+
+func malachiBook(verses ...int) *BookEnhanced {
+	chapter := &ChapterEnhanced{Nb: 3}
+	for _, nb := range verses {
+		chapter.Verses = append(chapter.Verses, roundTripVerse("Malachi", 3, nb, "", "verse text"))
+	}
+	return &BookEnhanced{Title: "Malachi", Chapters: []*ChapterEnhanced{chapter}}
+}
+
+func TestParallelVerses(t *testing.T) {
+	useTranslations(t)
+	registerTranslation("KJV", "King James Version", []*BookEnhanced{malachiBook(16, 17, 18)})
+	registerTranslation("heb", "Hebrew numbering", []*BookEnhanced{malachiBook(16, 17, 18, 19)})
+	registerTranslation("part", "Partial", []*BookEnhanced{malachiBook(16)})
+
+	type row struct {
+		verse    int
+		unmapped []string
+	}
+	tests := []struct {
+		ref   string
+		codes []string
+		rows  []row
+	}{
+		{"Mal 3:16-17", []string{"kjv", "heb"}, []row{{16, nil}, {17, nil}}},
+		{"Mal 3:17-18", []string{"kjv", "heb"}, []row{{17, nil}, {18, nil}, {19, []string{"kjv"}}}},
+		{"Mal 3:18", []string{"kjv"}, []row{{18, nil}}},
+		{"Mal 3:16-17", []string{"KJV", "part", "heb"}, []row{{16, nil}, {17, []string{"part"}}}},
+	}
+	for _, test := range tests {
+		r, err := parseReference(test.ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := parallelVerses(r, test.codes)
+		if err != nil {
+			t.Errorf("parallelVerses(%s, %v): %v", test.ref, test.codes, err)
+			continue
+		}
+		var got []row
+		for _, p := range rows {
+			got = append(got, row{p.ID.Verse(), p.Unmapped})
+			if len(p.Verses)+len(p.Unmapped) != len(test.codes) {
+				t.Errorf("%s is in %d translations and missing from %v, expecting %d translations", p.ID, len(p.Verses), p.Unmapped, len(test.codes))
+			}
+		}
+		if !reflect.DeepEqual(got, test.rows) {
+			t.Errorf("parallelVerses(%s, %v) = %v, expecting %v", test.ref, test.codes, got, test.rows)
+		}
+	}
+	if _, err := parallelVerses(VerseRange{Start: newVerseID(39, 3, 16), End: newVerseID(39, 3, 16)}, []string{"kjv", "web"}); err == nil {
+		t.Error("parallelVerses accepted an unknown translation")
+	}
+}
+
+func TestLoadTranslation(t *testing.T) {
+	useTranslations(t)
+	dir := t.TempDir()
+	bytes, err := json.Marshal(&BookEnhanced{Title: "Malachi", Chapters: []*ChapterEnhanced{{Nb: 3, Verses: []*VerseEnhanced{{Nb: 19, Text: "For, behold, the day cometh."}}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string][]byte{"Malachi.json": bytes, "translation.json": []byte(`{"name": "Hebrew numbering"}`)} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tr, err := loadTranslation("HEB", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.Code != "heb" || tr.Name != "Hebrew numbering" || getTranslation("heb") != tr {
+		t.Errorf("translation registered as %s %q", tr.Code, tr.Name)
+	}
+	if v := tr.Verses[newVerseID(39, 3, 19)]; v == nil || v.OSIS != "Mal.3.19" {
+		t.Errorf("Mal.3.19 not indexed: %v", tr.Verses)
+	}
+	if _, err := loadTranslation("empty", t.TempDir()); err == nil {
+		t.Error("loadTranslation accepted a directory without books")
+	}
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var typographyReportPath = "./typography.json"
+
+// TypographyOptions selects the normalizations of the verse text and titles,
+// the zero value leaves the text as is.
+type TypographyOptions struct {
+	NFC        bool   // compose the accented letters
+	Quotes     string // "", "straight" or "curly"
+	Dashes     string // "", "ascii" or "unicode"
+	Whitespace bool   // one plain space between words, none around
+}
+
+// defaultTypography follows the scraped text, which uses curly apostrophes.
+var defaultTypography = TypographyOptions{NFC: true, Quotes: "curly", Whitespace: true}
+
+// TypographyChange is one field changed by the normalization, Ref being the
+// osis id of the verse, or of the chapter for a superscription.
+type TypographyChange struct {
+	Ref    string `json:"ref"`
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// normalizeSpaces replaces the thin, non-breaking and other unicode spaces by
+// plain ones and drops the zero width ones, keeping every other character.
+func normalizeSpaces(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\u200b' || r == '\u200c' || r == '\u200d' || r == '\ufeff':
+			return -1
+		case unicode.Is(unicode.Zs, r):
+			return ' '
+		}
+		return r
+	}, text)
+}
+
+// rewriteRunes applies f to each rune of s, f returning the runes written so
+// far with r added, and gives the byte offset in the result of every byte
+// offset of s so the spans and line breaks can follow.
+func rewriteRunes(s string, f func(out []rune, r rune, next rune) []rune) (string, []int) {
+	var out []rune
+	pos := make([]int, len(s)+1)
+	for i, r := range s {
+		for j := i; j < i+utf8.RuneLen(r) && j < len(s); j++ {
+			pos[j] = len(out)
+		}
+		next, _ := utf8.DecodeRuneInString(s[i+utf8.RuneLen(r):])
+		out = f(out, r, next)
+	}
+	pos[len(s)] = len(out)
+
+	// rune positions to byte offsets, clamped as f may have dropped runes
+	offsets := make([]int, len(out)+1)
+	for i, r := range out {
+		offsets[i+1] = offsets[i] + utf8.RuneLen(r)
+	}
+	for i, p := range pos {
+		if p > len(out) {
+			p = len(out)
+		}
+		pos[i] = offsets[p]
+	}
+	return string(out), pos
+}
+
+func isOpening(prev []rune) bool {
+	return len(prev) == 0 || unicode.IsSpace(prev[len(prev)-1]) || strings.ContainsRune("([{‘“—–-", prev[len(prev)-1])
+}
+
+// quotedApostrophes tells, for each straight apostrophe of text in order,
+// whether it opens a quote closed by a later one, "'Tis" being an elision.
+func quotedApostrophes(text string) []bool {
+	var quoted []bool
+	var open []int
+	runes := []rune(text)
+	for i, r := range runes {
+		if r != '\'' {
+			continue
+		}
+		quoted = append(quoted, false)
+		next := utf8.RuneError
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+		switch {
+		case isOpening(runes[:i]) && unicode.IsLetter(next):
+			open = append(open, len(quoted)-1)
+		case len(open) > 0 && !unicode.IsLetter(next) && !unicode.IsDigit(next):
+			quoted[open[len(open)-1]] = true
+			open = open[:len(open)-1]
+		}
+	}
+	return quoted
+}
+
+// normalizeTypography applies the options to text, returning the new text and
+// the offset map of rewriteRunes.
+func normalizeTypography(text string, opts TypographyOptions) (string, []int) {
+	var quoted []bool
+	if opts.Quotes == "curly" {
+		quoted = quotedApostrophes(text)
+	}
+	apostrophes := 0
+	return rewriteRunes(text, func(out []rune, r rune, next rune) []rune {
+		if opts.NFC && r >= utf8.RuneSelf {
+			// a mark composed with the letter before it, rune by rune so the offsets follow
+			if len(out) > 0 && unicode.Is(unicode.M, r) {
+				if c := []rune(norm.NFC.String(string(out[len(out)-1]) + string(r))); len(c) == 1 {
+					out[len(out)-1] = c[0]
+					return out
+				}
+			}
+			if c := []rune(norm.NFC.String(string(r))); len(c) == 1 {
+				r = c[0]
+			}
+		}
+		switch opts.Quotes {
+		case "straight":
+			switch r {
+			case '‘', '’', '\u201b', '\u2032':
+				r = '\''
+			case '“', '”', '„', '\u2033':
+				r = '"'
+			}
+		case "curly":
+			switch r {
+			case '\'':
+				if quoted[apostrophes] {
+					r = '‘'
+				} else {
+					r = '’'
+				}
+				apostrophes++
+			case '"':
+				if isOpening(out) {
+					r = '“'
+				} else {
+					r = '”'
+				}
+			}
+		}
+		switch {
+		case r == '\u2010' || r == '\u2011':
+			r = '-'
+		case opts.Dashes == "ascii" && (r == '–' || r == '\u2012'):
+			r = '-'
+		case opts.Dashes == "ascii" && (r == '—' || r == '\u2015'):
+			return append(out, '-', '-')
+		case opts.Dashes == "unicode" && r == '-' && len(out) > 0 && out[len(out)-1] == '-':
+			out[len(out)-1] = '—'
+			return out
+		case opts.Dashes == "unicode" && r == '-' && len(out) > 0 && unicode.IsDigit(out[len(out)-1]) && unicode.IsDigit(next):
+			r = '–'
+		}
+		if opts.Whitespace {
+			if r == '\u200b' || r == '\u200c' || r == '\u200d' || r == '\ufeff' {
+				return out
+			}
+			if unicode.IsSpace(r) {
+				if len(out) == 0 || out[len(out)-1] == ' ' || next == utf8.RuneError {
+					return out
+				}
+				r = ' '
+			}
+		}
+		return append(out, r)
+	})
+}
+
+// trimTrailingSpace is needed as a space is only dropped at the end when it is
+// the last rune, "a \u200b" keeping its space.
+func trimTrailingSpace(text string, pos []int) (string, []int) {
+	trimmed := strings.TrimRight(text, " ")
+	for i, p := range pos {
+		if p > len(trimmed) {
+			pos[i] = len(trimmed)
+		}
+	}
+	return trimmed, pos
+}
+
+// normalizeText applies the default typography to a scraped text or title.
+func normalizeText(text string) string {
+	text, _ = normalizeTypography(text, defaultTypography)
+	return strings.TrimRight(text, " ")
+}
+
+// normalizeLines normalizes each line of an introduction or summary, keeping
+// the line breaks between the paragraphs.
+func normalizeLines(text string, opts TypographyOptions) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i], _ = normalizeTypography(line, opts)
+		if opts.Whitespace {
+			lines[i] = strings.TrimRight(lines[i], " ")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// normalizeVerseTypography normalizes the text, titles and tokens of a verse,
+// moving its spans and line breaks with the text.
+func normalizeVerseTypography(v *VerseEnhanced, opts TypographyOptions) []TypographyChange {
+	var changes []TypographyChange
+	field := func(name string, value *string) {
+		after, pos := normalizeTypography(*value, opts)
+		if opts.Whitespace {
+			after, pos = trimTrailingSpace(after, pos)
+		}
+		if after == *value {
+			return
+		}
+		changes = append(changes, TypographyChange{Ref: v.ID.OSIS(), Field: name, Before: *value, After: after})
+		if name == "text" {
+			for i := range v.Spans {
+				v.Spans[i].Start, v.Spans[i].End = pos[v.Spans[i].Start], pos[v.Spans[i].End]
+			}
+			for i := range v.Lines {
+				v.Lines[i] = pos[v.Lines[i]]
+			}
+		}
+		*value = after
+	}
+	field("text", &v.Text)
+	field("title", &v.Title)
+	field("subtitle", &v.Subtitle)
+	field("stanzaHeading", &v.StanzaHeading)
+	tokens := normalizeTokens(v.Tokens, opts)
+	for i := range tokens {
+		if tokens[i].Text != v.Tokens[i].Text {
+			changes = append(changes, TypographyChange{Ref: v.ID.OSIS(), Field: fmt.Sprintf("tokens.%d", i), Before: v.Tokens[i].Text, After: tokens[i].Text})
+		}
+	}
+	v.Tokens = tokens
+	return changes
+}
+
+// normalizeTokens normalizes the tokens of a verse as one text, so a quote is
+// curled by its place in the verse and joining the tokens still gives the text.
+func normalizeTokens(tokens []Token, opts TypographyOptions) []Token {
+	if tokens == nil {
+		return nil
+	}
+	after, pos := normalizeTypography(tokensText(tokens), opts)
+	if opts.Whitespace {
+		after, pos = trimTrailingSpace(after, pos)
+	}
+	normalized := make([]Token, len(tokens))
+	start := 0
+	for i, t := range tokens {
+		end := start + len(t.Text)
+		normalized[i] = Token{Text: after[pos[start]:pos[end]], Strongs: t.Strongs}
+		start = end
+	}
+	return normalized
+}
+
+// normalizeEnhancedTypography normalizes every verse, book introduction,
+// chapter superscription and summary of the enhanced books, returning what changed.
+func normalizeEnhancedTypography(opts TypographyOptions) []TypographyChange {
+	var changes []TypographyChange
+	field := func(ref, name string, value *string) {
+		if after := normalizeLines(*value, opts); after != *value {
+			changes = append(changes, TypographyChange{Ref: ref, Field: name, Before: *value, After: after})
+			*value = after
+		}
+	}
+	for _, book := range enhanced {
+		info := mustBookInfo(book.Title)
+		field(info.OSIS, "introduction", &book.Introduction)
+		for _, c := range book.Chapters {
+			ref := fmt.Sprintf("%s.%d", info.OSIS, c.Nb)
+			field(ref, "superscription", &c.Superscription)
+			field(ref, "summary", &c.Summary)
+			for _, v := range c.Verses {
+				changes = append(changes, normalizeVerseTypography(v, opts)...)
+			}
+		}
+	}
+	resetWordIndexes()
+	return changes
+}
+
+// typographyFlags adds the typography options to the flags of a command, the
+// returned function giving them once the flags are parsed.
+func typographyFlags(flags *flag.FlagSet) func() TypographyOptions {
+	nfc := flags.Bool("nfc", defaultTypography.NFC, "compose the accented letters")
+	quotes := flags.String("quotes", defaultTypography.Quotes, "curly, straight or keep")
+	dashes := flags.String("dashes", "keep", "ascii, unicode or keep")
+	spaces := flags.Bool("spaces", defaultTypography.Whitespace, "normalize the whitespace")
+	return func() TypographyOptions {
+		opts := TypographyOptions{NFC: *nfc, Quotes: *quotes, Dashes: *dashes, Whitespace: *spaces}
+		if opts.Quotes == "keep" {
+			opts.Quotes = ""
+		}
+		if opts.Dashes == "keep" {
+			opts.Dashes = ""
+		}
+		if opts.Quotes != "" && opts.Quotes != "curly" && opts.Quotes != "straight" {
+			log.Fatalf("unknown quotes style: %s, expecting curly, straight or keep", *quotes)
+		}
+		if opts.Dashes != "" && opts.Dashes != "ascii" && opts.Dashes != "unicode" {
+			log.Fatalf("unknown dashes style: %s, expecting ascii, unicode or keep", *dashes)
+		}
+		return opts
+	}
+}
+
+func typographyCommand(args []string) {
+	flags := flag.NewFlagSet("typography", flag.ExitOnError)
+	typography := typographyFlags(flags)
+	report := flags.String("report", typographyReportPath, "report of the changed verses")
+	write := flags.Bool("write", false, "rewrite the enhanced books, only the report is written otherwise")
+	_ = flags.Parse(args)
+	opts := typography()
+
+	loadEnhancedData()
+	changes := normalizeEnhancedTypography(opts)
+	if changes == nil {
+		changes = []TypographyChange{}
+	}
+	bytes, err := json.MarshalIndent(changes, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	if err := ioutil.WriteFile(*report, bytes, 0777); err != nil {
+		panic(err)
+	}
+	fmt.Println("wrote file: ", *report)
+
+	verses := make(map[string]bool)
+	for _, c := range changes {
+		verses[c.Ref] = true
+	}
+	fmt.Printf("normalized %v fields of %v verses\n", len(changes), len(verses))
+	if *write && len(changes) > 0 {
+		writeEnhancedBooks(os.Stdout)
+	}
+}
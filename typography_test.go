@@ -0,0 +1,105 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeTypography(t *testing.T) {
+	tests := []struct {
+		text string
+		opts TypographyOptions
+		want string
+	}{
+		{"the LORD's house", defaultTypography, "the LORD’s house"},
+		{"'Tis the LORD's doing", defaultTypography, "’Tis the LORD’s doing"},
+		{"he said, 'Go in peace.'", defaultTypography, "he said, ‘Go in peace.’"},
+		{`and said, "Peace be unto you."`, defaultTypography, "and said, “Peace be unto you.”"},
+		{"Moses' song", defaultTypography, "Moses’ song"},
+		{"the LORD’s “house”", TypographyOptions{Quotes: "straight"}, `the LORD's "house"`},
+		{"the LORD’s house", TypographyOptions{}, "the LORD’s house"},
+		{"in  the\u00a0beginning\u2009God \u200b", defaultTypography, "in the beginning God "},
+		{"Gene\u0300se", defaultTypography, "Gen\u00e8se"},
+		{"Gene\u0300se", TypographyOptions{}, "Gene\u0300se"},
+		{"verses 1-3--and more\u2011so", TypographyOptions{Dashes: "unicode"}, "verses 1–3—and more-so"},
+		{"verses 1–3—and more", TypographyOptions{Dashes: "ascii"}, "verses 1-3--and more"},
+	}
+	for _, test := range tests {
+		if got, _ := normalizeTypography(test.text, test.opts); got != test.want {
+			t.Errorf("normalizeTypography(%q, %+v) = %q, expecting %q", test.text, test.opts, got, test.want)
+		}
+	}
+}
+
+func TestNormalizeTypographyOffsets(t *testing.T) {
+	text := "the LORD's  house"
+	after, pos := normalizeTypography(text, defaultTypography)
+	if after != "the LORD’s house" {
+		t.Fatalf("normalized to %q", after)
+	}
+	tests := []struct {
+		offset, want int
+	}{
+		{0, 0},
+		{4, 4},
+		{8, 8},
+		{9, 11},
+		{12, 13},
+		{len(text), len(after)},
+	}
+	for _, test := range tests {
+		if pos[test.offset] != test.want {
+			t.Errorf("offset %d moved to %d, expecting %d", test.offset, pos[test.offset], test.want)
+		}
+	}
+}
+
+func TestQuotedApostrophes(t *testing.T) {
+	tests := []struct {
+		text string
+		want []bool
+	}{
+		{"the LORD's house", []bool{false}},
+		{"'Tis so", []bool{false}},
+		{"he said, 'Go.' and 'Stay'", []bool{true, false, true, false}},
+		{"the brethren' feet", []bool{false}},
+	}
+	for _, test := range tests {
+		if got := quotedApostrophes(test.text); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("quotedApostrophes(%q) = %v, expecting %v", test.text, got, test.want)
+		}
+	}
+}
+
+func TestNormalizeVerseTypography(t *testing.T) {
+	v := roundTripVerse("Psalms", 23, 3, "The LORD's  Way", "he leadeth me for his name's sake. ",
+		Span{Start: 20, End: 34, Style: SpanSupplied})
+	v.Lines = []int{20}
+	changes := normalizeVerseTypography(v, defaultTypography)
+	if v.Text != "he leadeth me for his name’s sake." || v.Title != "The LORD’s Way" {
+		t.Errorf("verse normalized to %q titled %q", v.Text, v.Title)
+	}
+	if want := []Span{{Start: 20, End: 36, Style: SpanSupplied}}; !reflect.DeepEqual(v.Spans, want) {
+		t.Errorf("spans moved to %v, expecting %v", v.Spans, want)
+	}
+	if v.Lines[0] != 20 {
+		t.Errorf("line break moved to %d, expecting 20", v.Lines[0])
+	}
+	var fields []string
+	for _, c := range changes {
+		fields = append(fields, c.Field)
+	}
+	if want := []string{"text", "title"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("changed %v, expecting %v", fields, want)
+	}
+	if changes := normalizeVerseTypography(v, defaultTypography); len(changes) != 0 {
+		t.Errorf("normalizing twice changed %v", changes)
+	}
+}
+
+func TestNormalizeLines(t *testing.T) {
+	text := "The book of  praises.\n\nDavid's psalms."
+	if got := normalizeLines(text, defaultTypography); got != "The book of praises.\n\nDavid’s psalms." {
+		t.Errorf("normalizeLines(%q) = %q", text, got)
+	}
+}
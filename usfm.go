@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+var usfmPath = "./usfm"
+
+func bookUSFM(book *BookEnhanced) string {
+	info := mustBookInfo(book.Title)
+	var b strings.Builder
+	fmt.Fprintf(&b, "\\id %s King James Version\n", info.USFM)
+	b.WriteString("\\usfm 3.0\n")
+	fmt.Fprintf(&b, "\\h %s\n", info.DisplayName())
+	fmt.Fprintf(&b, "\\toc1 %s\n", info.DisplayName())
+	fmt.Fprintf(&b, "\\toc2 %s\n", info.DisplayName())
+	fmt.Fprintf(&b, "\\toc3 %s\n", info.DisplayAbbreviation())
+	fmt.Fprintf(&b, "\\mt1 %s\n", info.DisplayName())
+	for _, par := range strings.Split(book.Introduction, "\n\n") {
+		if par = strings.TrimSpace(par); par != "" {
+			fmt.Fprintf(&b, "\\ip %s\n", strings.Join(strings.Fields(par), " "))
+		}
+	}
+	poetic := info.Poetic()
+	for _, c := range book.Chapters {
+		fmt.Fprintf(&b, "\\c %d\n", c.Nb)
+		if c.Summary != "" {
+			fmt.Fprintf(&b, "\\cd %s\n", strings.Join(strings.Fields(c.Summary), " "))
+		}
+		if c.Superscription != "" {
+			fmt.Fprintf(&b, "\\d %s\n", c.Superscription)
+		}
+		for i, v := range c.Verses {
+			if v.Title != "" {
+				fmt.Fprintf(&b, "\\s1 %s\n", v.Title)
+			}
+			if poetic {
+				if v.Stanza && v.Title == "" && i > 0 {
+					b.WriteString("\\b\n")
+				}
+				if v.StanzaHeading != "" {
+					fmt.Fprintf(&b, "\\qa %s\n", v.StanzaHeading)
+				}
+				for n, line := range verseLines(v) {
+					if n == 0 {
+						fmt.Fprintf(&b, "\\q1 \\v %d %s\n", v.Nb, textUSFM(line.Text, line.Spans))
+					} else {
+						fmt.Fprintf(&b, "\\q2 %s\n", textUSFM(line.Text, line.Spans))
+					}
+				}
+				continue
+			}
+			if i == 0 || v.Title != "" || v.Paragraph {
+				b.WriteString("\\p\n")
+			}
+			fmt.Fprintf(&b, "\\v %d %s\n", v.Nb, verseUSFM(v))
+		}
+	}
+	return b.String()
+}
+
+func writeUSFMBooks(dir string) {
+	os.RemoveAll(dir)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		panic(err)
+	}
+	for _, book := range enhanced {
+		info := mustBookInfo(book.Title)
+		fileName := fmt.Sprintf("%s/%02d%s.usfm", dir, info.Nb, info.USFM)
+		if err := ioutil.WriteFile(fileName, []byte(bookUSFM(book)), 0777); err != nil {
+			panic(err)
+		}
+		fmt.Println("wrote file: ", fileName)
+	}
+	fmt.Printf("wrote a total of %v books!\n", len(enhanced))
+}
+
+func usfmCommand(args []string) {
+	fs := flag.NewFlagSet("usfm", flag.ExitOnError)
+	out := fs.String("out", usfmPath, "output directory")
+	lang := localeFlag(fs)
+	_ = fs.Parse(args)
+	useLocale(*lang)
+
+	loadEnhancedData()
+	writeUSFMBooks(*out)
+}
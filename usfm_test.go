@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestBookUSFM(t *testing.T) {
+	useDisplayLocale(t, "en")
+	blessed := roundTripVerse("Matthew", 5, 3, "The Beatitudes", "Blessed are the poor in spirit: for theirs is the kingdom of heaven.",
+		Span{Start: 0, End: 68, Style: SpanWordsOfJesus})
+	mourn := roundTripVerse("Matthew", 5, 4, "", "Blessed are they that mourn: for they shall be comforted.",
+		Span{Start: 0, End: 57, Style: SpanWordsOfJesus}, Span{Start: 12, End: 16, Style: SpanSupplied})
+	book := &BookEnhanced{
+		Title:        "Matthew",
+		Introduction: "The gospel of the kingdom.\n\nWritten by   Matthew.",
+		Chapters:     []*ChapterEnhanced{{Nb: 5, Summary: "The sermon on the mount.", Verses: []*VerseEnhanced{blessed, mourn}}},
+	}
+	want := `\id MAT King James Version
+\usfm 3.0
+\h Matthew
+\toc1 Matthew
+\toc2 Matthew
+\toc3 Matt
+\mt1 Matthew
+\ip The gospel of the kingdom.
+\ip Written by Matthew.
+\c 5
+\cd The sermon on the mount.
+\s1 The Beatitudes
+\p
+\v 3 \wj Blessed are the poor in spirit: for theirs is the kingdom of heaven.\wj*
+\v 4 \wj Blessed are \+add they\+add* that mourn: for they shall be comforted.\wj*
+`
+	if got := bookUSFM(book); got != want {
+		t.Errorf("bookUSFM(Matthew) =\n%s\nexpecting\n%s", got, want)
+	}
+}
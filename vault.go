@@ -0,0 +1,142 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var vaultPath = "./vault"
+
+func vaultNoteName(info *BookInfo, chapter int) string {
+	return fmt.Sprintf("%s %d", info.DisplayName(), chapter)
+}
+
+// chapterNote renders a chapter as a markdown note, each verse being its own
+// block with a ^v<nb> id so it can be linked as [[Genesis 1#^v3]].
+func chapterNote(info *BookInfo, chapter *ChapterEnhanced, prev, next string) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "book: %q\n", info.DisplayName())
+	fmt.Fprintf(&b, "chapter: %d\n", chapter.Nb)
+	fmt.Fprintf(&b, "verses: %d\n", len(chapter.Verses))
+	fmt.Fprintf(&b, "testament: %s\n", info.Testament)
+	fmt.Fprintf(&b, "genre: %q\n", string(info.Genre))
+	fmt.Fprintf(&b, "osis: %s.%d\n", info.OSIS, chapter.Nb)
+	fmt.Fprintf(&b, "aliases: [%q]\n", fmt.Sprintf("%s %d", info.SBL, chapter.Nb))
+	fmt.Fprintf(&b, "tags: [bible, %s]\n", strings.ToLower(info.FileName()))
+	b.WriteString("---\n\n")
+
+	nav := func() {
+		var links []string
+		if prev != "" {
+			links = append(links, fmt.Sprintf("[[%s|← %s]]", prev, prev))
+		}
+		links = append(links, fmt.Sprintf("[[%s]]", info.DisplayName()))
+		if next != "" {
+			links = append(links, fmt.Sprintf("[[%s|%s →]]", next, next))
+		}
+		b.WriteString(strings.Join(links, " | ") + "\n\n")
+	}
+
+	nav()
+	fmt.Fprintf(&b, "# %s\n\n", vaultNoteName(info, chapter.Nb))
+	if chapter.Summary != "" {
+		b.WriteString("> " + strings.Join(strings.Fields(chapter.Summary), " ") + "\n\n")
+	}
+	if chapter.Superscription != "" {
+		b.WriteString("*" + chapter.Superscription + "*\n\n")
+	}
+	opts := readOptions{markdown: true}
+	for _, v := range chapter.Verses {
+		if v.Title != "" {
+			fmt.Fprintf(&b, "## %s\n\n", v.Title)
+		}
+		if v.StanzaHeading != "" {
+			fmt.Fprintf(&b, "### %s\n\n", v.StanzaHeading)
+		}
+		var lines []string
+		for _, line := range verseLines(v) {
+			lines = append(lines, readVerseText(line.Text, line.Spans, opts))
+		}
+		fmt.Fprintf(&b, "**%d** %s ^v%d\n\n", v.Nb, strings.Join(lines, "  \n"), v.Nb)
+	}
+	nav()
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+func bookNote(info *BookInfo, book *BookEnhanced) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "book: %q\n", info.DisplayName())
+	fmt.Fprintf(&b, "chapters: %d\n", len(book.Chapters))
+	fmt.Fprintf(&b, "testament: %s\n", info.Testament)
+	fmt.Fprintf(&b, "genre: %q\n", string(info.Genre))
+	fmt.Fprintf(&b, "aliases: [%q, %q]\n", info.SBL, info.OSIS)
+	b.WriteString("---\n\n")
+	fmt.Fprintf(&b, "# %s\n\n", info.DisplayName())
+	if book.Introduction != "" {
+		b.WriteString(strings.TrimSpace(book.Introduction) + "\n\n")
+	}
+	for _, c := range book.Chapters {
+		fmt.Fprintf(&b, "- [[%s]]\n", vaultNoteName(info, c.Nb))
+	}
+	return b.String()
+}
+
+// writeVault writes one note per chapter in a folder per book, plus a note per book listing its chapters.
+func writeVault(dir string) {
+	os.RemoveAll(dir)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		panic(err)
+	}
+
+	type vaultChapter struct {
+		info    *BookInfo
+		chapter *ChapterEnhanced
+	}
+	var chapters []vaultChapter
+	for _, book := range enhanced {
+		info := mustBookInfo(book.Title)
+		folder := filepath.Join(dir, fmt.Sprintf("%02d %s", info.Nb, info.DisplayName()))
+		if err := os.MkdirAll(folder, 0777); err != nil {
+			panic(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(folder, info.DisplayName()+".md"), []byte(bookNote(info, book)), 0777); err != nil {
+			panic(err)
+		}
+		for _, c := range book.Chapters {
+			chapters = append(chapters, vaultChapter{info: info, chapter: c})
+		}
+	}
+
+	for i, vc := range chapters {
+		prev, next := "", ""
+		if i > 0 {
+			prev = vaultNoteName(chapters[i-1].info, chapters[i-1].chapter.Nb)
+		}
+		if i < len(chapters)-1 {
+			next = vaultNoteName(chapters[i+1].info, chapters[i+1].chapter.Nb)
+		}
+		name := vaultNoteName(vc.info, vc.chapter.Nb)
+		file := filepath.Join(dir, fmt.Sprintf("%02d %s", vc.info.Nb, vc.info.DisplayName()), name+".md")
+		if err := ioutil.WriteFile(file, []byte(chapterNote(vc.info, vc.chapter, prev, next)), 0777); err != nil {
+			panic(err)
+		}
+	}
+	fmt.Printf("wrote %v chapter notes to %s!\n", len(chapters), dir)
+}
+
+func vaultCommand(args []string) {
+	flags := flag.NewFlagSet("vault", flag.ExitOnError)
+	out := flags.String("out", vaultPath, "output directory")
+	lang := localeFlag(flags)
+	_ = flags.Parse(args)
+	useLocale(*lang)
+
+	loadEnhancedData()
+	writeVault(*out)
+}
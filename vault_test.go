@@ -0,0 +1,76 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChapterNote(t *testing.T) {
+	useDisplayLocale(t, "en")
+	chapter := &ChapterEnhanced{Nb: 1, Summary: "Naomi goes to Moab.", Verses: []*VerseEnhanced{
+		roundTripVerse("Ruth", 1, 1, "Naomi and Ruth", "Now it came to pass in the days when the judges ruled."),
+		roundTripVerse("Ruth", 1, 2, "", "And the name of the man was Elimelech.", Span{Start: 24, End: 27, Style: SpanSupplied}),
+	}}
+	want := `---
+book: "Ruth"
+chapter: 1
+verses: 2
+testament: OT
+genre: "History"
+osis: Ruth.1
+aliases: ["Ruth 1"]
+tags: [bible, ruth]
+---
+
+[[Judges 21|← Judges 21]] | [[Ruth]] | [[Ruth 2|Ruth 2 →]]
+
+# Ruth 1
+
+> Naomi goes to Moab.
+
+## Naomi and Ruth
+
+**1** Now it came to pass in the days when the judges ruled. ^v1
+
+**2** And the name of the man *was* Elimelech. ^v2
+
+[[Judges 21|← Judges 21]] | [[Ruth]] | [[Ruth 2|Ruth 2 →]]
+`
+	if got := chapterNote(mustBookInfo("Ruth"), chapter, "Judges 21", "Ruth 2"); got != want {
+		t.Errorf("chapterNote(Ruth 1) =\n%s\nexpecting\n%s", got, want)
+	}
+}
+
+func TestWriteVault(t *testing.T) {
+	useDisplayLocale(t, "en")
+	useBooks(t, []*BookEnhanced{
+		{Title: "Ruth", Chapters: []*ChapterEnhanced{{Nb: 4, Verses: []*VerseEnhanced{
+			roundTripVerse("Ruth", 4, 22, "", "And Obed begat Jesse, and Jesse begat David."),
+		}}}},
+		{Title: "1 Samuel", Chapters: []*ChapterEnhanced{{Nb: 1, Verses: []*VerseEnhanced{
+			roundTripVerse("1 Samuel", 1, 1, "", "Now there was a certain man of Ramathaimzophim."),
+		}}}},
+	})
+	dir := t.TempDir()
+	writeVault(dir)
+	tests := []struct {
+		file, content string
+	}{
+		{"08 Ruth/Ruth.md", "- [[Ruth 4]]\n"},
+		{"08 Ruth/Ruth 4.md", "[[Ruth]] | [[1 Samuel 1|1 Samuel 1 →]]"},
+		{"09 1 Samuel/1 Samuel.md", "aliases: [\"1 Sam\", \"1Sam\"]\n"},
+		{"09 1 Samuel/1 Samuel 1.md", "[[Ruth 4|← Ruth 4]] | [[1 Samuel]]\n"},
+	}
+	for _, test := range tests {
+		bytes, err := ioutil.ReadFile(filepath.Join(dir, test.file))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if !strings.Contains(string(bytes), test.content) {
+			t.Errorf("%s doesn't contain %q:\n%s", test.file, test.content, bytes)
+		}
+	}
+}
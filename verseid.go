@@ -0,0 +1,280 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// VerseID identifies a verse as BBCCCVVV, e.g. Genesis 1:1 is 1001001 and
+// Revelation 22:21 is 66022021. Book numbers follow the catalogue order.
+type VerseID int
+
+// VerseRange is an inclusive range of verses.
+type VerseRange struct {
+	Start VerseID `json:"start"`
+	End   VerseID `json:"end"`
+}
+
+var referenceRegex = regexp.MustCompile(`^(.*?[^\d\s.])\.?\s*(\d+)(?:\s*:\s*(\d+))?(?:\s*[-–]\s*(?:(\d+)\s*:\s*)?(\d+))?$`)
+
+// verseOffsets holds, per book, the number of verses preceding each chapter
+// in the whole canon so that ids can be turned into an ordinal and back.
+var verseOffsets [][]int
+var totalVerses int
+
+func init() {
+	verseOffsets = make([][]int, len(catalogue))
+	for i, b := range catalogue {
+		verseOffsets[i] = make([]int, len(b.Verses))
+		for c, n := range b.Verses {
+			verseOffsets[i][c] = totalVerses
+			totalVerses += n
+		}
+	}
+}
+
+func newVerseID(book, chapter, verse int) VerseID {
+	return VerseID(book*1000000 + chapter*1000 + verse)
+}
+
+func (id VerseID) Book() int {
+	return int(id) / 1000000
+}
+
+func (id VerseID) Chapter() int {
+	return int(id) / 1000 % 1000
+}
+
+func (id VerseID) Verse() int {
+	return int(id) % 1000
+}
+
+func (id VerseID) BookInfo() *BookInfo {
+	return bookByNb(id.Book())
+}
+
+// Valid reports whether the id points to a verse that exists in the KJV.
+func (id VerseID) Valid() bool {
+	info := id.BookInfo()
+	if info == nil {
+		return false
+	}
+	return id.Verse() >= 1 && id.Verse() <= info.VerseCount(id.Chapter())
+}
+
+func (id VerseID) OSIS() string {
+	info := id.BookInfo()
+	if info == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s.%d.%d", info.OSIS, id.Chapter(), id.Verse())
+}
+
+func (id VerseID) String() string {
+	info := id.BookInfo()
+	if info == nil {
+		return strconv.Itoa(int(id))
+	}
+	return fmt.Sprintf("%s %d:%d", info.Name, id.Chapter(), id.Verse())
+}
+
+// ordinal is the 0 based position of the verse in the whole canon.
+func (id VerseID) ordinal() int {
+	if !id.Valid() {
+		return -1
+	}
+	return verseOffsets[id.Book()-1][id.Chapter()-1] + id.Verse() - 1
+}
+
+func verseIDFromOrdinal(ord int) VerseID {
+	if ord < 0 || ord >= totalVerses {
+		return 0
+	}
+	for b, offsets := range verseOffsets {
+		end := totalVerses
+		if b+1 < len(verseOffsets) {
+			end = verseOffsets[b+1][0]
+		}
+		if ord >= end {
+			continue
+		}
+		for c := len(offsets) - 1; c >= 0; c-- {
+			if offsets[c] <= ord {
+				return newVerseID(b+1, c+1, ord-offsets[c]+1)
+			}
+		}
+	}
+	return 0
+}
+
+// Next returns the following verse, crossing chapters and books, or 0 after Revelation 22:21.
+func (id VerseID) Next() VerseID {
+	if !id.Valid() {
+		return 0
+	}
+	return verseIDFromOrdinal(id.ordinal() + 1)
+}
+
+// Prev returns the preceding verse, or 0 before Genesis 1:1.
+func (id VerseID) Prev() VerseID {
+	if !id.Valid() {
+		return 0
+	}
+	return verseIDFromOrdinal(id.ordinal() - 1)
+}
+
+// verseDistance is the number of verses from a to b, negative when b comes first.
+func verseDistance(a, b VerseID) int {
+	return b.ordinal() - a.ordinal()
+}
+
+func parseOSISID(osisID string) (VerseID, error) {
+	id, err := splitOSISID(osisID)
+	if err != nil {
+		return 0, err
+	}
+	if !id.Valid() {
+		return 0, fmt.Errorf("verse does not exist: %s", osisID)
+	}
+	return id, nil
+}
+
+// splitOSISID reads an osis id without checking the verse exists in the KJV.
+func splitOSISID(osisID string) (VerseID, error) {
+	parts := strings.Split(strings.TrimSpace(osisID), ".")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid osis id: %s", osisID)
+	}
+	info := bookInfo(parts[0])
+	if info == nil {
+		return 0, fmt.Errorf("unknown book in osis id: %s", osisID)
+	}
+	chapter, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid chapter in osis id: %s", osisID)
+	}
+	verse, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return 0, fmt.Errorf("invalid verse in osis id: %s", osisID)
+	}
+	return newVerseID(info.Nb, chapter, verse), nil
+}
+
+// parseReference understands references such as "John 3:16", "Gen 1:1-5",
+// "1 Sam 3", "Ruth 1:22-2:3", "Jude 1:25-Rev 1:1", "Gen 1:1-Gen 1:5", "Gen.1.1"
+// and plain book names. In the books of one chapter "Jude 1" is the chapter
+// while a number past 1 can only be a verse, "Jude 5" for "Jude 1:5".
+func parseReference(ref string) (VerseRange, error) {
+	ref = strings.TrimSpace(ref)
+	if id, err := parseOSISID(ref); err == nil {
+		return VerseRange{Start: id, End: id}, nil
+	}
+	if info := bookInfo(ref); info != nil {
+		last := info.Chapters()
+		return VerseRange{
+			Start: newVerseID(info.Nb, 1, 1),
+			End:   newVerseID(info.Nb, last, info.VerseCount(last)),
+		}, nil
+	}
+
+	// range repeating the book name, e.g. "Jude 1:20-Rev 1:3" or "Gen 1:1-Gen 1:5"
+	if i := strings.IndexAny(ref, "-–"); i != -1 {
+		if from, err := parseReference(ref[:i]); err == nil {
+			if to, err := parseReference(strings.TrimLeft(ref[i:], "-–")); err == nil {
+				if to.End < from.Start {
+					return VerseRange{}, fmt.Errorf("reference ends before it starts: %s", ref)
+				}
+				return VerseRange{Start: from.Start, End: to.End}, nil
+			}
+		}
+	}
+
+	m := referenceRegex.FindStringSubmatch(ref)
+	if m == nil {
+		return VerseRange{}, fmt.Errorf("invalid reference: %s", ref)
+	}
+	info := bookInfo(m[1])
+	if info == nil {
+		return VerseRange{}, fmt.Errorf("unknown book in reference: %s", ref)
+	}
+	chapter, _ := strconv.Atoi(m[2])
+	if info.Chapters() == 1 && m[3] == "" && m[4] == "" && (chapter > 1 || m[5] != "" && m[5] != "1") {
+		// "Jude 5" and "Jude 3-5" are verses of the only chapter
+		m[3] = m[2]
+		chapter = 1
+	}
+	if info.VerseCount(chapter) == 0 {
+		return VerseRange{}, fmt.Errorf("%s has no chapter %d", info.Name, chapter)
+	}
+
+	var r VerseRange
+	switch {
+	case m[3] == "" && m[5] == "":
+		// whole chapter
+		r.Start = newVerseID(info.Nb, chapter, 1)
+		r.End = newVerseID(info.Nb, chapter, info.VerseCount(chapter))
+	case m[3] == "" && m[4] != "":
+		// chapter to verse, e.g. Genesis 1-2:5
+		endChapter, _ := strconv.Atoi(m[4])
+		endVerse, _ := strconv.Atoi(m[5])
+		r.Start = newVerseID(info.Nb, chapter, 1)
+		r.End = newVerseID(info.Nb, endChapter, endVerse)
+	case m[3] == "":
+		// chapter range, e.g. Genesis 1-3
+		endChapter, _ := strconv.Atoi(m[5])
+		r.Start = newVerseID(info.Nb, chapter, 1)
+		r.End = newVerseID(info.Nb, endChapter, info.VerseCount(endChapter))
+	default:
+		verse, _ := strconv.Atoi(m[3])
+		r.Start = newVerseID(info.Nb, chapter, verse)
+		r.End = r.Start
+		if m[5] != "" {
+			endChapter := chapter
+			if m[4] != "" {
+				endChapter, _ = strconv.Atoi(m[4])
+			}
+			endVerse, _ := strconv.Atoi(m[5])
+			r.End = newVerseID(info.Nb, endChapter, endVerse)
+		}
+	}
+	if !r.Start.Valid() || !r.End.Valid() {
+		return VerseRange{}, fmt.Errorf("verse does not exist: %s", ref)
+	}
+	if r.End < r.Start {
+		return VerseRange{}, fmt.Errorf("reference ends before it starts: %s", ref)
+	}
+	return r, nil
+}
+
+func (r VerseRange) Contains(id VerseID) bool {
+	return id >= r.Start && id <= r.End
+}
+
+// Len is the number of verses in the range.
+func (r VerseRange) Len() int {
+	return verseDistance(r.Start, r.End) + 1
+}
+
+// IDs lists every verse of the range in order.
+func (r VerseRange) IDs() []VerseID {
+	var ids []VerseID
+	for id := r.Start; id != 0 && id <= r.End; id = id.Next() {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (r VerseRange) String() string {
+	if r.Start == r.End {
+		return r.Start.String()
+	}
+	if r.Start.Book() != r.End.Book() {
+		return r.Start.String() + "-" + r.End.String()
+	}
+	if r.Start.Chapter() != r.End.Chapter() {
+		return fmt.Sprintf("%s-%d:%d", r.Start.String(), r.End.Chapter(), r.End.Verse())
+	}
+	return fmt.Sprintf("%s-%d", r.Start.String(), r.End.Verse())
+}
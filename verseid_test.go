@@ -0,0 +1,126 @@
+package main
+
+import "testing"
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		ref        string
+		start, end VerseID
+	}{
+		{"John 3:16", newVerseID(43, 3, 16), newVerseID(43, 3, 16)},
+		{"Gen 1:1-5", newVerseID(1, 1, 1), newVerseID(1, 1, 5)},
+		{"Gen 1", newVerseID(1, 1, 1), newVerseID(1, 1, 31)},
+		{"Gen 1-2", newVerseID(1, 1, 1), newVerseID(1, 2, 25)},
+		{"Gen 1-2:5", newVerseID(1, 1, 1), newVerseID(1, 2, 5)},
+		{"Gen 1:3-2:5", newVerseID(1, 1, 3), newVerseID(1, 2, 5)},
+		{"Gen 1:1-Gen 1:5", newVerseID(1, 1, 1), newVerseID(1, 1, 5)},
+		{"Ruth 1:22-2:3", newVerseID(8, 1, 22), newVerseID(8, 2, 3)},
+		{"Jude 1:20-Rev 1:3", newVerseID(65, 1, 20), newVerseID(66, 1, 3)},
+		{"1 Sam 3", newVerseID(9, 3, 1), newVerseID(9, 3, 21)},
+		{"Gen.1.1", newVerseID(1, 1, 1), newVerseID(1, 1, 1)},
+		{"Ruth", newVerseID(8, 1, 1), newVerseID(8, 4, 22)},
+		{"Jude 1:5", newVerseID(65, 1, 5), newVerseID(65, 1, 5)},
+		{"Jude 1", newVerseID(65, 1, 1), newVerseID(65, 1, 25)},
+		{"Jude 5", newVerseID(65, 1, 5), newVerseID(65, 1, 5)},
+		{"Jude 1-3", newVerseID(65, 1, 1), newVerseID(65, 1, 3)},
+		{"Jude 3-5", newVerseID(65, 1, 3), newVerseID(65, 1, 5)},
+		{"Obadiah 1", newVerseID(31, 1, 1), newVerseID(31, 1, 21)},
+		{"Philemon 1", newVerseID(57, 1, 1), newVerseID(57, 1, 25)},
+		{"2 John 1", newVerseID(63, 1, 1), newVerseID(63, 1, 13)},
+		{"3 John 1", newVerseID(64, 1, 1), newVerseID(64, 1, 14)},
+		{"3 John 1:14", newVerseID(64, 1, 14), newVerseID(64, 1, 14)},
+	}
+	for _, test := range tests {
+		r, err := parseReference(test.ref)
+		if err != nil {
+			t.Errorf("parseReference(%q): %v", test.ref, err)
+			continue
+		}
+		if r.Start != test.start || r.End != test.end {
+			t.Errorf("parseReference(%q) = %s-%s, expecting %s-%s", test.ref, r.Start, r.End, test.start, test.end)
+		}
+	}
+}
+
+func TestParseReferenceErrors(t *testing.T) {
+	for _, ref := range []string{"", "Hezekiah 1:1", "Gen 51", "Gen 1:32", "Gen 2-1", "Gen 1:5-Gen 1:1", "Mal.3.19"} {
+		if r, err := parseReference(ref); err == nil {
+			t.Errorf("parseReference(%q) = %s, expecting an error", ref, r)
+		}
+	}
+}
+
+func TestVerseID(t *testing.T) {
+	tests := []struct {
+		id         VerseID
+		osis, name string
+		valid      bool
+		prev, next VerseID
+	}{
+		{newVerseID(1, 1, 1), "Gen.1.1", "Genesis 1:1", true, 0, newVerseID(1, 1, 2)},
+		{newVerseID(1, 1, 31), "Gen.1.31", "Genesis 1:31", true, newVerseID(1, 1, 30), newVerseID(1, 2, 1)},
+		{newVerseID(39, 4, 6), "Mal.4.6", "Malachi 4:6", true, newVerseID(39, 4, 5), newVerseID(40, 1, 1)},
+		{newVerseID(62, 5, 21), "1John.5.21", "1 John 5:21", true, newVerseID(62, 5, 20), newVerseID(63, 1, 1)},
+		{newVerseID(66, 22, 21), "Rev.22.21", "Revelation 22:21", true, newVerseID(66, 22, 20), 0},
+		{newVerseID(39, 3, 19), "Mal.3.19", "Malachi 3:19", false, 0, 0},
+		{newVerseID(67, 1, 1), "", "67001001", false, 0, 0},
+	}
+	for _, test := range tests {
+		if test.id.OSIS() != test.osis || test.id.String() != test.name || test.id.Valid() != test.valid {
+			t.Errorf("%d is %q, %q and valid %v, expecting %q, %q and %v",
+				int(test.id), test.id.OSIS(), test.id.String(), test.id.Valid(), test.osis, test.name, test.valid)
+		}
+		if test.id.Prev() != test.prev || test.id.Next() != test.next {
+			t.Errorf("%s comes after %s and before %s, expecting %s and %s", test.id, test.id.Prev(), test.id.Next(), test.prev, test.next)
+		}
+	}
+}
+
+func TestParseOSISID(t *testing.T) {
+	tests := []struct {
+		osisID string
+		id     VerseID
+		valid  bool
+	}{
+		{"Gen.1.1", newVerseID(1, 1, 1), true},
+		{" 1John.5.7 ", newVerseID(62, 5, 7), true},
+		{"Ps.119.176", newVerseID(19, 119, 176), true},
+		{"Mal.3.19", newVerseID(39, 3, 19), false},
+	}
+	for _, test := range tests {
+		id, err := splitOSISID(test.osisID)
+		if err != nil || id != test.id {
+			t.Errorf("splitOSISID(%q) = %s, %v, expecting %s", test.osisID, id, err, test.id)
+		}
+		if _, err := parseOSISID(test.osisID); (err == nil) != test.valid {
+			t.Errorf("parseOSISID(%q) error is %v, expecting valid %v", test.osisID, err, test.valid)
+		}
+	}
+	for _, osisID := range []string{"", "Gen.1", "Hez.1.1", "Gen.one.1", "Gen.1.x"} {
+		if id, err := splitOSISID(osisID); err == nil {
+			t.Errorf("splitOSISID(%q) = %s, expecting an error", osisID, id)
+		}
+	}
+}
+
+func TestVerseRange(t *testing.T) {
+	tests := []struct {
+		start, end VerseID
+		name       string
+		count      int
+	}{
+		{newVerseID(43, 3, 16), newVerseID(43, 3, 16), "John 3:16", 1},
+		{newVerseID(1, 1, 1), newVerseID(1, 1, 5), "Genesis 1:1-5", 5},
+		{newVerseID(8, 1, 22), newVerseID(8, 2, 3), "Ruth 1:22-2:3", 4},
+		{newVerseID(65, 1, 24), newVerseID(66, 1, 2), "Jude 1:24-Revelation 1:2", 4},
+	}
+	for _, test := range tests {
+		r := VerseRange{Start: test.start, End: test.end}
+		if r.String() != test.name || r.Len() != test.count || len(r.IDs()) != test.count {
+			t.Errorf("%s has %d verses and %d ids, expecting %s of %d verses", r, r.Len(), len(r.IDs()), test.name, test.count)
+		}
+		if !r.Contains(test.end) || r.Contains(test.end.Next()) {
+			t.Errorf("%s doesn't contain its last verse only", r)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var versificationPath = "./json/versification"
+
+const kjvScheme = "kjv"
+
+// Versification maps the verses of a numbering scheme to the KJV ones. The
+// verses not in the table keep their number, unless that number is taken by
+// another mapping.
+type Versification struct {
+	Name    string
+	toKJV   map[VerseID][]VerseID
+	fromKJV map[VerseID][]VerseID
+}
+
+var versifications = make(map[string]*Versification)
+
+// loadVersification reads a mapping table, each line holding a verse or
+// range of the scheme, a tab and the KJV verse or range, e.g.
+// "Mal.3.19-Mal.3.24	Mal.4.1-Mal.4.6". A "-" maps to no KJV verse.
+func loadVersification(name, file string) (*Versification, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open versification %s: %w", file, err)
+	}
+	defer f.Close()
+
+	s := &Versification{Name: name, toKJV: make(map[VerseID][]VerseID), fromKJV: make(map[VerseID][]VerseID)}
+	scanner := bufio.NewScanner(f)
+	lineNb := 0
+	for scanner.Scan() {
+		lineNb++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		cols := strings.Split(line, "\t")
+		if len(cols) != 2 {
+			return nil, fmt.Errorf("%s:%d: expecting 2 columns", file, lineNb)
+		}
+		from, err := schemeIDs(cols[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", file, lineNb, err)
+		}
+		var to []VerseID
+		if strings.TrimSpace(cols[1]) != "-" {
+			r, err := parseCrossRefTarget(cols[1])
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", file, lineNb, err)
+			}
+			to = r.IDs()
+		}
+		switch {
+		case len(to) == 0:
+			for _, id := range from {
+				s.toKJV[id] = nil
+			}
+		case len(from) == len(to):
+			for i, id := range from {
+				s.toKJV[id] = []VerseID{to[i]}
+				s.fromKJV[to[i]] = append(s.fromKJV[to[i]], id)
+			}
+		case len(from) == 1:
+			s.toKJV[from[0]] = to
+			for _, id := range to {
+				s.fromKJV[id] = append(s.fromKJV[id], from[0])
+			}
+		case len(to) == 1:
+			for _, id := range from {
+				s.toKJV[id] = to
+			}
+			s.fromKJV[to[0]] = from
+		default:
+			return nil, fmt.Errorf("%s:%d: ranges of %d and %d verses", file, lineNb, len(from), len(to))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	versifications[name] = s
+	return s, nil
+}
+
+// loadVersifications loads every table of dir, named after its file.
+func loadVersifications(dir string) {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.tsv"))
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".tsv")
+		if _, err := loadVersification(name, file); err != nil {
+			logError(err)
+		}
+	}
+}
+
+func getVersification(name string) (*Versification, error) {
+	name = strings.ToLower(name)
+	if name == kjvScheme || name == "" {
+		return nil, nil
+	}
+	if len(versifications) == 0 {
+		loadVersifications(versificationPath)
+	}
+	s, ok := versifications[name]
+	if !ok {
+		var names []string
+		for n := range versifications {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown versification: %s, expecting kjv or one of %v", name, names)
+	}
+	return s, nil
+}
+
+// schemeIDs expands an osis id or a range within a chapter, "Ps.3.2-Ps.3.9",
+// the verses needing not to exist in the KJV.
+func schemeIDs(s string) ([]VerseID, error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	start, err := splitOSISID(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	end := start
+	if len(parts) == 2 {
+		if end, err = splitOSISID(parts[1]); err != nil {
+			return nil, err
+		}
+	}
+	if len(parts) > 2 || end.Book() != start.Book() || end.Chapter() != start.Chapter() || end < start {
+		return nil, fmt.Errorf("invalid range, expecting one within a chapter: %s", s)
+	}
+	var ids []VerseID
+	for id := start; id <= end; id++ {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// ToKJV converts a verse of the scheme, it maps to no verse when the KJV
+// doesn't number it, like a psalm title, and to several when the KJV splits it.
+func (s *Versification) ToKJV(id VerseID) []VerseID {
+	if s == nil {
+		return []VerseID{id}
+	}
+	if ids, ok := s.toKJV[id]; ok {
+		return ids
+	}
+	if _, taken := s.fromKJV[id]; taken || !id.Valid() {
+		return nil
+	}
+	return []VerseID{id}
+}
+
+// FromKJV converts a KJV verse to the scheme.
+func (s *Versification) FromKJV(id VerseID) []VerseID {
+	if s == nil {
+		return []VerseID{id}
+	}
+	if ids, ok := s.fromKJV[id]; ok {
+		return ids
+	}
+	if _, taken := s.toKJV[id]; taken || !id.Valid() {
+		return nil
+	}
+	return []VerseID{id}
+}
+
+// exists reports whether the scheme numbers the verse.
+func (s *Versification) exists(id VerseID) bool {
+	if _, ok := s.toKJV[id]; ok {
+		return true
+	}
+	_, taken := s.fromKJV[id]
+	return !taken && id.Valid()
+}
+
+// VerseCount is the number of verses of a chapter in the scheme.
+func (s *Versification) VerseCount(info *BookInfo, chapter int) int {
+	count := 0
+	for v := info.VerseCount(chapter); v > 0; v-- {
+		if s.exists(newVerseID(info.Nb, chapter, v)) {
+			count = v
+			break
+		}
+	}
+	for id := range s.toKJV {
+		if id.Book() == info.Nb && id.Chapter() == chapter && id.Verse() > count {
+			count = id.Verse()
+		}
+	}
+	return count
+}
+
+// convertVerse converts a verse between two schemes, through the KJV.
+func convertVerse(id VerseID, from, to string) ([]VerseID, error) {
+	src, err := getVersification(from)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := getVersification(to)
+	if err != nil {
+		return nil, err
+	}
+	var ids []VerseID
+	seen := make(map[VerseID]bool)
+	for _, kjv := range src.ToKJV(id) {
+		for _, out := range dst.FromKJV(kjv) {
+			if !seen[out] {
+				seen[out] = true
+				ids = append(ids, out)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// schemeReference reads a reference numbered in the scheme and returns the
+// matching KJV range, e.g. "Joel 3:1" in the hebrew scheme is Joel 2:28.
+func schemeReference(ref, scheme string) (VerseRange, error) {
+	s, err := getVersification(scheme)
+	if err != nil {
+		return VerseRange{}, err
+	}
+	if s == nil {
+		return parseReference(ref)
+	}
+
+	ref = strings.TrimSpace(ref)
+	var start, end VerseID
+	if ids, err := schemeIDs(ref); err == nil {
+		start, end = ids[0], ids[len(ids)-1]
+	} else {
+		m := referenceRegex.FindStringSubmatch(ref)
+		if m == nil {
+			return VerseRange{}, fmt.Errorf("invalid reference: %s", ref)
+		}
+		info := bookInfo(m[1])
+		if info == nil {
+			return VerseRange{}, fmt.Errorf("unknown book in reference: %s", ref)
+		}
+		chapter, _ := strconv.Atoi(m[2])
+		if info.Chapters() == 1 && m[3] == "" && m[4] == "" && (chapter > 1 || m[5] != "" && m[5] != "1") {
+			// "Jude 5" and "Jude 3-5" are verses of the only chapter
+			m[3] = m[2]
+			chapter = 1
+		}
+		switch {
+		case m[3] == "" && m[5] == "":
+			start = newVerseID(info.Nb, chapter, 1)
+			end = newVerseID(info.Nb, chapter, s.VerseCount(info, chapter))
+		case m[3] == "" && m[4] != "":
+			// chapter to verse, e.g. Joel 3-4:5
+			endChapter, _ := strconv.Atoi(m[4])
+			endVerse, _ := strconv.Atoi(m[5])
+			start = newVerseID(info.Nb, chapter, 1)
+			end = newVerseID(info.Nb, endChapter, endVerse)
+		case m[3] == "":
+			endChapter, _ := strconv.Atoi(m[5])
+			start = newVerseID(info.Nb, chapter, 1)
+			end = newVerseID(info.Nb, endChapter, s.VerseCount(info, endChapter))
+		default:
+			verse, _ := strconv.Atoi(m[3])
+			start = newVerseID(info.Nb, chapter, verse)
+			end = start
+			if m[5] != "" {
+				endChapter := chapter
+				if m[4] != "" {
+					endChapter, _ = strconv.Atoi(m[4])
+				}
+				endVerse, _ := strconv.Atoi(m[5])
+				end = newVerseID(info.Nb, endChapter, endVerse)
+			}
+		}
+	}
+	if end < start {
+		return VerseRange{}, fmt.Errorf("reference ends before it starts: %s", ref)
+	}
+
+	var r VerseRange
+	info := start.BookInfo()
+	for chapter := start.Chapter(); chapter <= end.Chapter(); chapter++ {
+		last := s.VerseCount(info, chapter)
+		for v := 1; v <= last; v++ {
+			id := newVerseID(info.Nb, chapter, v)
+			if id < start || id > end {
+				continue
+			}
+			for _, kjv := range s.ToKJV(id) {
+				if r.Start == 0 || kjv < r.Start {
+					r.Start = kjv
+				}
+				if kjv > r.End {
+					r.End = kjv
+				}
+			}
+		}
+	}
+	if r.Start == 0 {
+		return VerseRange{}, fmt.Errorf("%s has no KJV verse in the %s versification", ref, s.Name)
+	}
+	return r, nil
+}
+
+func versifyCommand(args []string) {
+	fs := flag.NewFlagSet("versify", flag.ExitOnError)
+	from := fs.String("from", kjvScheme, "versification of the reference")
+	to := fs.String("to", kjvScheme, "versification to convert to")
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: versify [-from hebrew] [-to vulgate] <reference>")
+		os.Exit(2)
+	}
+
+	r, err := schemeReference(strings.Join(fs.Args(), " "), *from)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%s is %s in the KJV\n", strings.Join(fs.Args(), " "), r)
+	if strings.ToLower(*to) == kjvScheme {
+		return
+	}
+	for _, kjv := range r.IDs() {
+		ids, err := convertVerse(kjv, kjvScheme, *to)
+		if err != nil {
+			log.Fatal(err)
+		}
+		var out []string
+		for _, id := range ids {
+			out = append(out, fmt.Sprintf("%s %d:%d", id.BookInfo().Name, id.Chapter(), id.Verse()))
+		}
+		if len(out) == 0 {
+			out = append(out, "-")
+		}
+		fmt.Printf("%s\t%s\n", kjv, strings.Join(out, ", "))
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func osisIDs(t *testing.T, refs ...string) []VerseID {
+	var ids []VerseID
+	for _, ref := range refs {
+		id, err := splitOSISID(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestVersificationToKJV(t *testing.T) {
+	hebrew, err := getVersification("hebrew")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		id   string
+		want []string
+	}{
+		{"Joel.3.1", []string{"Joel.2.28"}},
+		{"Joel.4.21", []string{"Joel.3.21"}},
+		{"Mal.3.19", []string{"Mal.4.1"}},
+		{"Ps.3.1", nil},
+		{"Ps.3.2", []string{"Ps.3.1"}},
+		{"Gen.1.1", []string{"Gen.1.1"}},
+		{"Gen.32.1", []string{"Gen.31.55"}},
+		{"Gen.32.33", []string{"Gen.32.32"}},
+	}
+	for _, test := range tests {
+		got := hebrew.ToKJV(osisIDs(t, test.id)[0])
+		if want := osisIDs(t, test.want...); !reflect.DeepEqual(got, want) {
+			t.Errorf("hebrew %s is %v in the KJV, expecting %v", test.id, got, want)
+		}
+	}
+}
+
+func TestVersificationFromKJV(t *testing.T) {
+	hebrew, err := getVersification("hebrew")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		id   string
+		want []string
+	}{
+		{"Joel.2.28", []string{"Joel.3.1"}},
+		{"Mal.4.6", []string{"Mal.3.24"}},
+		{"Ps.3.1", []string{"Ps.3.2"}},
+		{"Gen.31.55", []string{"Gen.32.1"}},
+		{"John.3.16", []string{"John.3.16"}},
+	}
+	for _, test := range tests {
+		got := hebrew.FromKJV(osisIDs(t, test.id)[0])
+		if want := osisIDs(t, test.want...); !reflect.DeepEqual(got, want) {
+			t.Errorf("KJV %s is %v in the hebrew scheme, expecting %v", test.id, got, want)
+		}
+	}
+}
+
+func TestVerseCount(t *testing.T) {
+	hebrew, err := getVersification("hebrew")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		book          string
+		chapter, want int
+	}{
+		{"Joel", 2, 27},
+		{"Joel", 3, 5},
+		{"Joel", 4, 21},
+		{"Malachi", 3, 24},
+		{"Psalms", 3, 9},
+		{"Genesis", 1, 31},
+	}
+	for _, test := range tests {
+		if got := hebrew.VerseCount(mustBookInfo(test.book), test.chapter); got != test.want {
+			t.Errorf("hebrew %s %d has %d verses, expecting %d", test.book, test.chapter, got, test.want)
+		}
+	}
+}
+
+func TestConvertVerse(t *testing.T) {
+	tests := []struct {
+		id, from, to string
+		want         []string
+	}{
+		{"Joel.3.1", "hebrew", "kjv", []string{"Joel.2.28"}},
+		{"Joel.2.28", "kjv", "hebrew", []string{"Joel.3.1"}},
+		{"Ps.9.21", "vulgate", "kjv", []string{"Ps.10.1"}},
+		{"Ps.10.1", "vulgate", "hebrew", []string{"Ps.11.1"}},
+		{"Ps.51.3", "hebrew", "vulgate", []string{"Ps.50.1"}},
+	}
+	for _, test := range tests {
+		got, err := convertVerse(osisIDs(t, test.id)[0], test.from, test.to)
+		if err != nil {
+			t.Errorf("convertVerse(%s, %s, %s): %v", test.id, test.from, test.to, err)
+			continue
+		}
+		if want := osisIDs(t, test.want...); !reflect.DeepEqual(got, want) {
+			t.Errorf("convertVerse(%s, %s, %s) = %v, expecting %v", test.id, test.from, test.to, got, want)
+		}
+	}
+	if _, err := convertVerse(newVerseID(1, 1, 1), "klingon", "kjv"); err == nil {
+		t.Error("convertVerse from an unknown scheme gave no error")
+	}
+}
+
+func TestSchemeReference(t *testing.T) {
+	tests := []struct {
+		ref, scheme string
+		start, end  string
+	}{
+		{"Joel 3:1", "hebrew", "Joel.2.28", "Joel.2.28"},
+		{"Joel 3", "hebrew", "Joel.2.28", "Joel.2.32"},
+		{"Joel 3-4", "hebrew", "Joel.2.28", "Joel.3.21"},
+		{"Joel 3-4:5", "hebrew", "Joel.2.28", "Joel.3.5"},
+		{"Joel 3:5-4:2", "hebrew", "Joel.2.32", "Joel.3.2"},
+		{"Ps 3", "hebrew", "Ps.3.1", "Ps.3.8"},
+		{"Ps.51.1-Ps.51.3", "hebrew", "Ps.51.1", "Ps.51.1"},
+		{"Obadiah 5", "hebrew", "Obad.1.5", "Obad.1.5"},
+		{"Obadiah 1", "hebrew", "Obad.1.1", "Obad.1.21"},
+		{"Joel 3:1", "kjv", "Joel.3.1", "Joel.3.1"},
+	}
+	for _, test := range tests {
+		r, err := schemeReference(test.ref, test.scheme)
+		if err != nil {
+			t.Errorf("schemeReference(%q, %s): %v", test.ref, test.scheme, err)
+			continue
+		}
+		if want := osisIDs(t, test.start, test.end); r.Start != want[0] || r.End != want[1] {
+			t.Errorf("schemeReference(%q, %s) = %s, expecting %s-%s", test.ref, test.scheme, r, want[0], want[1])
+		}
+	}
+	for _, ref := range []string{"Ps 3:1", "Joel 4-3", "Hezekiah 1"} {
+		if r, err := schemeReference(ref, "hebrew"); err == nil {
+			t.Errorf("schemeReference(%q, hebrew) = %s, expecting an error", ref, r)
+		}
+	}
+}
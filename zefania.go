@@ -0,0 +1,306 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var zefaniaPath = "./kjv-zefania.xml"
+var openSongPath = "./kjv-opensong.xml"
+var xmlTags = regexp.MustCompile(`<[^>]*>`)
+
+type zefaniaBible struct {
+	XMLName   xml.Name      `xml:"XMLBIBLE"`
+	BibleName string        `xml:"biblename,attr"`
+	Type      string        `xml:"type,attr"`
+	Status    string        `xml:"status,attr"`
+	Version   string        `xml:"version,attr"`
+	Info      zefaniaInfo   `xml:"INFORMATION"`
+	Books     []zefaniaBook `xml:"BIBLEBOOK"`
+}
+
+type zefaniaInfo struct {
+	Title      string `xml:"title"`
+	Identifier string `xml:"identifier"`
+	Language   string `xml:"language"`
+	Rights     string `xml:"rights"`
+}
+
+type zefaniaBook struct {
+	Nb        int              `xml:"bnumber,attr"`
+	Name      string           `xml:"bname,attr,omitempty"`
+	ShortName string           `xml:"bsname,attr,omitempty"`
+	Chapters  []zefaniaChapter `xml:"CHAPTER"`
+}
+
+type zefaniaChapter struct {
+	Nb    int           `xml:"cnumber,attr"`
+	Items []zefaniaItem `xml:",any"`
+}
+
+// zefaniaItem is a CAPTION or a VERS of a chapter, kept in document order so
+// a caption stays in front of the verse it heads. The inner xml keeps the
+// STYLE, gr and NOTE elements of the format, use xmlText to get the plain text.
+type zefaniaItem struct {
+	XMLName xml.Name
+	Verse   int    `xml:"vref,attr,omitempty"`
+	Nb      int    `xml:"vnumber,attr,omitempty"`
+	Content string `xml:",innerxml"`
+}
+
+func zefaniaCaption(verse int, title string) zefaniaItem {
+	return zefaniaItem{XMLName: xml.Name{Local: "CAPTION"}, Verse: verse, Content: escapeXML(title)}
+}
+
+func zefaniaVerse(nb int, content string) zefaniaItem {
+	return zefaniaItem{XMLName: xml.Name{Local: "VERS"}, Nb: nb, Content: content}
+}
+
+type openSongBible struct {
+	XMLName xml.Name       `xml:"bible"`
+	Books   []openSongBook `xml:"b"`
+}
+
+type openSongBook struct {
+	Name     string            `xml:"n,attr"`
+	Chapters []openSongChapter `xml:"c"`
+}
+
+type openSongChapter struct {
+	Nb     int             `xml:"n,attr"`
+	Verses []openSongVerse `xml:"v"`
+}
+
+type openSongVerse struct {
+	Nb   int    `xml:"n,attr"`
+	Text string `xml:",chardata"`
+}
+
+var zefaniaStyles = map[string]string{
+	SpanWordsOfJesus: "color:#FF0000",
+	SpanSupplied:     "font-style:italic",
+}
+
+func escapeXML(s string) string {
+	var b bytes.Buffer
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// xmlText strips the markup of an inner xml string.
+func xmlText(content string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(xmlTags.ReplaceAllString(content, ""))), " ")
+}
+
+// bookNumbers numbers the books in the order of Books.json.
+func bookNumbers() map[string]int {
+	names, err := readBookNames()
+	if err != nil {
+		panic(err)
+	}
+	numbers := make(map[string]int)
+	for i, name := range names {
+		numbers[name] = i + 1
+	}
+	return numbers
+}
+
+func zefaniaFromEnhanced(title string) *zefaniaBible {
+	bible := &zefaniaBible{
+		BibleName: title,
+		Type:      "x-bible",
+		Status:    "v",
+		Version:   "2.0.0.0",
+		Info: zefaniaInfo{
+			Title:      title,
+			Identifier: "KJV",
+			Language:   "ENG",
+			Rights:     "Public Domain",
+		},
+	}
+	numbers := bookNumbers()
+	for _, book := range enhanced {
+		info := mustBookInfo(book.Title)
+		zb := zefaniaBook{Nb: numbers[info.Name], Name: info.DisplayName(), ShortName: info.OSIS}
+		for _, c := range book.Chapters {
+			zc := zefaniaChapter{Nb: c.Nb}
+			for _, v := range c.Verses {
+				if v.Title != "" {
+					zc.Items = append(zc.Items, zefaniaCaption(v.Nb, v.Title))
+				}
+				content := renderSpans(v.Text, v.Spans,
+					func(style string, depth int) string { return `<STYLE css="` + zefaniaStyles[style] + `">` },
+					func(style string, depth int) string { return `</STYLE>` },
+					escapeXML)
+				zc.Items = append(zc.Items, zefaniaVerse(v.Nb, content))
+			}
+			zb.Chapters = append(zb.Chapters, zc)
+		}
+		bible.Books = append(bible.Books, zb)
+	}
+	return bible
+}
+
+func openSongFromEnhanced() *openSongBible {
+	bible := &openSongBible{}
+	for _, book := range enhanced {
+		ob := openSongBook{Name: mustBookInfo(book.Title).DisplayName()}
+		for _, c := range book.Chapters {
+			oc := openSongChapter{Nb: c.Nb}
+			for _, v := range c.Verses {
+				oc.Verses = append(oc.Verses, openSongVerse{Nb: v.Nb, Text: v.Text})
+			}
+			ob.Chapters = append(ob.Chapters, oc)
+		}
+		bible.Books = append(bible.Books, ob)
+	}
+	return bible
+}
+
+func writeXMLFile(file string, v interface{}) {
+	out, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	out = append([]byte(xml.Header), out...)
+	if err := ioutil.WriteFile(file, append(out, '\n'), 0777); err != nil {
+		panic(err)
+	}
+	fmt.Println("wrote file: ", file)
+}
+
+func readXMLFile(file string, v interface{}) error {
+	bytes, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if err := xml.Unmarshal(bytes, v); err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return nil
+}
+
+// compareVerse logs and counts the differences between a re-imported verse and the enhanced one.
+func compareVerse(format string, id VerseID, text string, seen map[VerseID]bool) int {
+	seen[id] = true
+	v, ok := enhancedVerses[id]
+	if !ok {
+		logError(fmt.Errorf("%s: unexpected verse %s", format, id))
+		return 1
+	}
+	if text != strings.Join(strings.Fields(v.Text), " ") {
+		logError(fmt.Errorf("%s: verse %s doesn't match: %s, expecting: %s", format, id, text, v.Text))
+		return 1
+	}
+	return 0
+}
+
+func missingVerses(format string, seen map[VerseID]bool) int {
+	missing := 0
+	for id := range enhancedVerses {
+		if !seen[id] {
+			logError(fmt.Errorf("%s: missing verse %s", format, id))
+			missing++
+		}
+	}
+	return missing
+}
+
+// verifyZefania re-imports the written file and compares every verse and section title.
+func verifyZefania(file string) int {
+	bible := new(zefaniaBible)
+	if err := readXMLFile(file, bible); err != nil {
+		logError(err)
+		return 1
+	}
+	names, _ := readBookNames()
+	errors := 0
+	seen := make(map[VerseID]bool)
+	for _, zb := range bible.Books {
+		if zb.Nb < 1 || zb.Nb > len(names) {
+			logError(fmt.Errorf("zefania: invalid book number %d", zb.Nb))
+			errors++
+			continue
+		}
+		info := mustBookInfo(names[zb.Nb-1])
+		for _, zc := range zb.Chapters {
+			for _, item := range zc.Items {
+				switch item.XMLName.Local {
+				case "VERS":
+					errors += compareVerse("zefania", newVerseID(info.Nb, zc.Nb, item.Nb), xmlText(item.Content), seen)
+				case "CAPTION":
+					if v, ok := enhancedVerses[newVerseID(info.Nb, zc.Nb, item.Verse)]; !ok || v.Title != xmlText(item.Content) {
+						logError(fmt.Errorf("zefania: caption of %s %d:%d doesn't match: %s", info.Name, zc.Nb, item.Verse, xmlText(item.Content)))
+						errors++
+					}
+				}
+			}
+		}
+	}
+	return errors + missingVerses("zefania", seen)
+}
+
+// verifyOpenSong re-imports the written file and compares every verse.
+func verifyOpenSong(file string) int {
+	bible := new(openSongBible)
+	if err := readXMLFile(file, bible); err != nil {
+		logError(err)
+		return 1
+	}
+	errors := 0
+	seen := make(map[VerseID]bool)
+	for _, ob := range bible.Books {
+		info := bookInfo(ob.Name)
+		if info == nil {
+			logError(fmt.Errorf("opensong: unknown book %s", ob.Name))
+			errors++
+			continue
+		}
+		for _, oc := range ob.Chapters {
+			for _, ov := range oc.Verses {
+				errors += compareVerse("opensong", newVerseID(info.Nb, oc.Nb, ov.Nb), strings.Join(strings.Fields(ov.Text), " "), seen)
+			}
+		}
+	}
+	return errors + missingVerses("opensong", seen)
+}
+
+func zefaniaCommand(args []string) {
+	flags := flag.NewFlagSet("zefania", flag.ExitOnError)
+	out := flags.String("out", zefaniaPath, "output file")
+	title := flags.String("title", "King James Version", "bible name")
+	lang := localeFlag(flags)
+	_ = flags.Parse(args)
+	useLocale(*lang)
+
+	loadEnhancedData()
+	writeXMLFile(*out, zefaniaFromEnhanced(*title))
+	if errors := verifyZefania(*out); errors > 0 {
+		fmt.Printf("round trip failed with %v differences\n", errors)
+		os.Exit(1)
+	}
+	fmt.Printf("round trip verified %v verses!\n", len(enhancedVerses))
+}
+
+func openSongCommand(args []string) {
+	flags := flag.NewFlagSet("opensong", flag.ExitOnError)
+	out := flags.String("out", openSongPath, "output file")
+	lang := localeFlag(flags)
+	_ = flags.Parse(args)
+	useLocale(*lang)
+
+	loadEnhancedData()
+	writeXMLFile(*out, openSongFromEnhanced())
+	if errors := verifyOpenSong(*out); errors > 0 {
+		fmt.Printf("round trip failed with %v differences\n", errors)
+		os.Exit(1)
+	}
+	fmt.Printf("round trip verified %v verses!\n", len(enhancedVerses))
+}
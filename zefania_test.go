@@ -0,0 +1,110 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func roundTripVerse(book string, chapter, nb int, title, text string, spans ...Span) *VerseEnhanced {
+	id := newVerseID(mustBookInfo(book).Nb, chapter, nb)
+	return &VerseEnhanced{ID: id, OSIS: id.OSIS(), Nb: nb, Title: title, Text: text, Spans: spans}
+}
+
+// roundTripBooks is a small bible with titles, spans and characters escaped in xml.
+func roundTripBooks() []*BookEnhanced {
+	return []*BookEnhanced{
+		{Title: "Genesis", Chapters: []*ChapterEnhanced{{Nb: 1, Verses: []*VerseEnhanced{
+			roundTripVerse("Genesis", 1, 1, "Creation of Heaven and Earth", "In the beginning God created the heaven and the earth."),
+			roundTripVerse("Genesis", 1, 2, "", "And the earth was without form, and void; and darkness was upon the face of the deep.",
+				Span{Start: 14, End: 17, Style: SpanSupplied}),
+			roundTripVerse("Genesis", 1, 3, "Creation of the Light", "And God said, Let there be light: and there was light."),
+		}}}},
+		{Title: "Matthew", Chapters: []*ChapterEnhanced{{Nb: 5, Verses: []*VerseEnhanced{
+			roundTripVerse("Matthew", 5, 3, "The Beatitudes", "Blessed are the poor in spirit: for theirs is the kingdom of heaven.",
+				Span{Start: 0, End: 68, Style: SpanWordsOfJesus}),
+			roundTripVerse("Matthew", 5, 4, "", "Blessed are they that mourn: for they shall be comforted & <rest>."),
+		}}}},
+		{Title: "Jude", Chapters: []*ChapterEnhanced{{Nb: 1, Verses: []*VerseEnhanced{
+			roundTripVerse("Jude", 1, 1, "", "Jude, the servant of Jesus Christ, and brother of James, to them that are sanctified by God the Father."),
+		}}}},
+	}
+}
+
+// useBooks makes books the enhanced books for the duration of the test.
+func useBooks(t *testing.T, books []*BookEnhanced) {
+	savedBooks, savedVerses := enhanced, enhancedVerses
+	t.Cleanup(func() {
+		enhanced, enhancedVerses = savedBooks, savedVerses
+		resetWordIndexes()
+	})
+	enhanced = books
+	enhancedVerses = make(map[VerseID]*VerseEnhanced)
+	resetWordIndexes()
+	for _, book := range books {
+		for _, c := range book.Chapters {
+			for _, v := range c.Verses {
+				enhancedVerses[v.ID] = v
+			}
+		}
+	}
+}
+
+func TestXMLText(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"In the beginning", "In the beginning"},
+		{`And the earth <STYLE css="font-style:italic">was</STYLE> without form`, "And the earth was without form"},
+		{"comforted &amp; &lt;rest&gt;.", "comforted & <rest>."},
+		{"  spaced\n  out ", "spaced out"},
+	}
+	for _, test := range tests {
+		if got := xmlText(test.content); got != test.want {
+			t.Errorf("xmlText(%q) = %q, expecting %q", test.content, got, test.want)
+		}
+		if got := xmlText(escapeXML(test.want)); got != test.want {
+			t.Errorf("xmlText(escapeXML(%q)) = %q", test.want, got)
+		}
+	}
+}
+
+func TestZefaniaExport(t *testing.T) {
+	useBooks(t, roundTripBooks())
+	file := filepath.Join(t.TempDir(), "bible.xml")
+	writeXMLFile(file, zefaniaFromEnhanced("Round Trip"))
+	if errors := verifyZefania(file); errors != 0 {
+		t.Errorf("verifyZefania found %d differences", errors)
+	}
+
+	bible := new(zefaniaBible)
+	if err := readXMLFile(file, bible); err != nil {
+		t.Fatal(err)
+	}
+	captions := 0
+	for _, zb := range bible.Books {
+		for _, zc := range zb.Chapters {
+			for i, item := range zc.Items {
+				if item.XMLName.Local != "CAPTION" {
+					continue
+				}
+				captions++
+				if i+1 == len(zc.Items) || zc.Items[i+1].XMLName.Local != "VERS" || zc.Items[i+1].Nb != item.Verse {
+					t.Errorf("caption %q of chapter %d isn't followed by verse %d", xmlText(item.Content), zc.Nb, item.Verse)
+				}
+			}
+		}
+	}
+	if captions != 3 {
+		t.Errorf("wrote %d captions, expecting 3", captions)
+	}
+}
+
+func TestOpenSongExport(t *testing.T) {
+	useBooks(t, roundTripBooks())
+	file := filepath.Join(t.TempDir(), "bible.xml")
+	writeXMLFile(file, openSongFromEnhanced())
+	if errors := verifyOpenSong(file); errors != 0 {
+		t.Errorf("verifyOpenSong found %d differences", errors)
+	}
+}